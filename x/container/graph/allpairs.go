@@ -0,0 +1,264 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// AllPairs holds the cheapest paths spanning every pair of vertices in a graph
+// at the time it was computed. It is immutable and safe for concurrent use.
+type AllPairs struct {
+	keys  []internal.Key
+	index map[internal.Key]int
+	dist  [][]int
+	next  [][]int // next[i][j] is the vertex after i on the path to j, or -1
+}
+
+// AllPairs computes the cheapest paths spanning every pair of vertices in g,
+// using Johnson's algorithm for sparse graphs and Floyd-Warshall for dense
+// graphs. Negative edge costs are supported; if g contains a negative cycle, a
+// *CycleError wrapping ErrNegativeCycle is returned.
+func (g *Graph) AllPairs() (*AllPairs, error) {
+	snap := g.snapshot()
+	if snap.dense() {
+		return floydWarshall(snap)
+	}
+
+	return johnson(snap)
+}
+
+// FloydWarshall computes the cheapest paths spanning every pair of vertices in
+// g using the Floyd-Warshall algorithm, which is best suited to dense graphs.
+func FloydWarshall(g *Graph) (*AllPairs, error) {
+	return floydWarshall(g.snapshot())
+}
+
+// Johnson computes the cheapest paths spanning every pair of vertices in g
+// using Johnson's algorithm, which is best suited to sparse graphs.
+func Johnson(g *Graph) (*AllPairs, error) {
+	return johnson(g.snapshot())
+}
+
+// Cost returns the cost of the cheapest path spanning from and to, and whether
+// such a path exists.
+func (a *AllPairs) Cost(from Key, to Key) (int, bool) {
+	i, j, ok := a.lookup(from, to)
+	if !ok || a.next[i][j] < 0 {
+		return 0, false
+	}
+
+	return a.dist[i][j], true
+}
+
+// Next returns the vertex following from on the cheapest path spanning from and
+// to, and whether such a path exists. If from and to are the same vertex, Next
+// returns from.
+func (a *AllPairs) Next(from Key, to Key) (Key, bool) {
+	i, j, ok := a.lookup(from, to)
+	if !ok || a.next[i][j] < 0 {
+		return _zeroKey, false
+	}
+
+	return newKey(a.keys[a.next[i][j]]), true
+}
+
+// Path returns the cheapest path spanning from and to. If no such path exists,
+// an empty path is returned.
+func (a *AllPairs) Path(from Key, to Key) Path {
+	i, j, ok := a.lookup(from, to)
+	if !ok || a.next[i][j] < 0 {
+		return Path{}
+	}
+
+	path := internal.Path{
+		Cost:     a.dist[i][j],
+		Vertices: []internal.Key{a.keys[i]},
+	}
+
+	for ; i != j; i = a.next[i][j] {
+		path.Vertices = append(path.Vertices, a.keys[a.next[i][j]])
+	}
+
+	return newPathFromInternal(path)
+}
+
+func (a *AllPairs) lookup(from Key, to Key) (int, int, bool) {
+	i, ok := a.index[from.key]
+	if !ok {
+		return 0, 0, false
+	}
+
+	j, ok := a.index[to.key]
+	if !ok {
+		return 0, 0, false
+	}
+
+	return i, j, true
+}
+
+func newAllPairs(snap *snapshot) *AllPairs {
+	n := len(snap.keys)
+
+	a := &AllPairs{
+		keys:  snap.keys,
+		index: snap.index,
+		dist:  make([][]int, n),
+		next:  make([][]int, n),
+	}
+
+	for i := 0; i < n; i++ {
+		a.dist[i] = make([]int, n)
+		a.next[i] = make([]int, n)
+
+		for j := range a.next[i] {
+			a.next[i][j] = -1
+		}
+
+		a.next[i][i] = i
+	}
+
+	return a
+}
+
+func floydWarshall(snap *snapshot) (*AllPairs, error) {
+	var (
+		a = newAllPairs(snap)
+		n = len(snap.keys)
+	)
+
+	a.addArcs(snap)
+
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			if a.next[i][k] < 0 {
+				continue
+			}
+
+			for j := 0; j < n; j++ {
+				if a.next[k][j] < 0 {
+					continue
+				}
+
+				cost := a.dist[i][k] + a.dist[k][j]
+				if a.next[i][j] < 0 || cost < a.dist[i][j] {
+					a.dist[i][j] = cost
+					a.next[i][j] = a.next[i][k]
+				}
+			}
+		}
+	}
+
+	if a.hasNegativeCycle() {
+		// Floyd-Warshall does not track the cycle itself, so it is extracted
+		// as Johnson's algorithm would.
+		_, err := snap.potentials()
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// addArcs records the cheapest edge spanning each pair of vertices in snap.
+func (a *AllPairs) addArcs(snap *snapshot) {
+	for i, arcs := range snap.arcs {
+		for _, arc := range arcs {
+			if a.next[i][arc.to] < 0 || arc.cost < a.dist[i][arc.to] {
+				a.dist[i][arc.to] = arc.cost
+				a.next[i][arc.to] = arc.to
+			}
+		}
+	}
+}
+
+// hasNegativeCycle reports whether any vertex lies on a negative cycle, once
+// Floyd-Warshall has run.
+func (a *AllPairs) hasNegativeCycle() bool {
+	for i := range a.dist {
+		if a.dist[i][i] < 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+func johnson(snap *snapshot) (*AllPairs, error) {
+	potential, err := snap.potentials()
+	if err != nil {
+		return nil, err
+	}
+
+	a := newAllPairs(snap)
+
+	for src := range snap.keys {
+		johnsonSearch(snap, potential, src, a.dist[src], a.next[src])
+	}
+
+	return a, nil
+}
+
+// johnsonSearch performs a Dijkstra search from src using edge costs reweighted
+// by potential, populating the dist and next rows for src.
+func johnsonSearch(
+	snap *snapshot,
+	potential []int,
+	src int,
+	dist []int,
+	next []int,
+) {
+	var (
+		best  = make([]int, len(snap.keys))
+		done  = make([]bool, len(snap.keys))
+		queue = internal.NewCostHeap(internal.Costed{Index: src})
+	)
+
+	for queue.Len() > 0 {
+		cur := queue.Pop()
+		if done[cur.Index] {
+			continue
+		}
+		done[cur.Index] = true
+
+		// Undo the reweighting to recover the original path cost.
+		dist[cur.Index] = cur.Cost - potential[src] + potential[cur.Index]
+
+		for _, edge := range snap.arcs[cur.Index] {
+			if done[edge.to] {
+				continue
+			}
+
+			cost := cur.Cost + edge.cost + potential[cur.Index] - potential[edge.to]
+			if next[edge.to] >= 0 && cost >= best[edge.to] {
+				continue
+			}
+
+			best[edge.to] = cost
+			if cur.Index == src {
+				next[edge.to] = edge.to
+			} else {
+				next[edge.to] = next[cur.Index]
+			}
+
+			queue.Push(internal.Costed{Index: edge.to, Cost: cost})
+		}
+	}
+}
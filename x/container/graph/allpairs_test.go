@@ -0,0 +1,192 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"errors"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllPairs(t *testing.T) {
+	cases := []struct {
+		name     string
+		edges    int
+		negative bool
+	}{
+		{name: "sparse", edges: 40},
+		{name: "dense", edges: 400},
+		{name: "negative sparse", edges: 40, negative: true},
+		{name: "negative dense", edges: 400, negative: true},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				rng  = rand.New(rand.NewSource(int64(i)))
+				g    = graph.New()
+				keys = make([]graph.Key, 20)
+			)
+
+			for i := range keys {
+				keys[i] = g.AddVertex(i)
+			}
+
+			for i := 0; i < tc.edges; i++ {
+				var (
+					from = rng.Intn(len(keys))
+					to   = rng.Intn(len(keys))
+					cost = rng.Intn(10)
+				)
+
+				// Negative edges only ever point "forward", and "backward"
+				// edges are expensive enough to preclude negative cycles.
+				if tc.negative && from < to {
+					cost -= 5
+				} else if tc.negative {
+					cost += 5 * len(keys)
+				}
+
+				g.AddEdgeCost(keys[from], keys[to], cost)
+			}
+
+			requireAllPairs(t, g, keys, graph.FloydWarshall)
+			requireAllPairs(t, g, keys, graph.Johnson)
+			requireAllPairs(t, g, keys, func(g *graph.Graph) (
+				*graph.AllPairs,
+				error,
+			) {
+				return g.AllPairs()
+			})
+		})
+	}
+}
+
+func TestAllPairsNegativeCycle(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+	)
+
+	g.AddEdgeCost(k1, k2, 1)
+	g.AddEdgeCost(k2, k3, -2)
+	g.AddEdgeCost(k3, k2, 1)
+	g.AddEdgeCost(k3, k4, 1)
+
+	algos := []func(*graph.Graph) (*graph.AllPairs, error){
+		graph.FloydWarshall,
+		graph.Johnson,
+	}
+
+	for i, algo := range algos {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			paths, err := algo(g)
+			require.Nil(t, paths)
+			require.True(t, errors.Is(err, graph.ErrNegativeCycle))
+
+			var cycleErr *graph.CycleError
+			require.True(t, errors.As(err, &cycleErr))
+			require.Equal(t, -1, cycleErr.Cycle.Cost)
+			require.ElementsMatch(
+				t,
+				[]graph.Key{k2, k3},
+				cycleErr.Cycle.Vertices[1:],
+			)
+		})
+	}
+}
+
+func TestAllPairsMissing(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	g.AddEdgeCost(k1, k2, 3)
+
+	paths, err := g.AllPairs()
+	require.NoError(t, err)
+
+	cost, ok := paths.Cost(k1, k2)
+	require.True(t, ok)
+	require.Equal(t, 3, cost)
+
+	next, ok := paths.Next(k1, k2)
+	require.True(t, ok)
+	require.Equal(t, k2, next)
+
+	next, ok = paths.Next(k1, k1)
+	require.True(t, ok)
+	require.Equal(t, k1, next)
+
+	_, ok = paths.Cost(k2, k1)
+	require.False(t, ok)
+
+	_, ok = paths.Next(k2, k1)
+	require.False(t, ok)
+
+	_, ok = paths.Cost(k1, graph.Key{})
+	require.False(t, ok)
+
+	_, ok = paths.Next(graph.Key{}, k1)
+	require.False(t, ok)
+
+	require.Equal(t, graph.Path{}, paths.Path(k2, k1))
+	require.Equal(t, graph.Path{}, paths.Path(graph.Key{}, k1))
+}
+
+func requireAllPairs(
+	t *testing.T,
+	g *graph.Graph,
+	keys []graph.Key,
+	algo func(*graph.Graph) (*graph.AllPairs, error),
+) {
+	paths, err := algo(g)
+	require.NoError(t, err)
+
+	for _, from := range keys {
+		for _, to := range keys {
+			var (
+				expected    = g.FindPath(graph.BellmanFord, from, to)
+				actual      = paths.Path(from, to)
+				cost, found = paths.Cost(from, to)
+			)
+
+			require.Equal(t, len(expected.Vertices) > 0, found)
+			require.Equal(t, expected.Cost, actual.Cost)
+			require.Equal(t, expected.Cost, cost)
+			require.Equal(t, actual.Cost, pathCost(t, g, actual))
+
+			if found {
+				require.Equal(t, from, actual.Vertices[0])
+				require.Equal(t, to, actual.Vertices[len(actual.Vertices)-1])
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"context"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// HeuristicFunc estimates the cost of the cheapest path spanning vertices from
+// and to.
+type HeuristicFunc = func(from Vertex, to Vertex) int
+
+// AStar returns a FindPathFunc that uses the A* search algorithm, guided by
+// heuristic, to find the cheapest path spanning two vertices.
+//
+// The returned path is only guaranteed to be the cheapest path if heuristic is
+// admissible (i.e., it never overestimates the remaining cost). A nil heuristic
+// is treated as always returning 0, which is equivalent to Dijkstra.
+func AStar(heuristic HeuristicFunc) FindPathFunc {
+	return AStarBy(heuristic, edgeCost)
+}
+
+// AStarBy returns a FindPathFunc that behaves identically to AStar, but uses
+// cost to determine the cost of each edge rather than Edge.Cost. Costs must not
+// be negative, and heuristic must be admissible with respect to cost.
+func AStarBy(heuristic HeuristicFunc, cost EdgeCostFunc) FindPathFunc {
+	return func(g *Graph, from Key, to Key) Path {
+		return astar(g, from.key, to.key, heuristic, cost)
+	}
+}
+
+// AStarContext returns a FindPathContextFunc that behaves identically to
+// AStar, but stops searching once its context is done. If the search is
+// stopped, the context's error is returned along with the cheapest path found
+// so far, if any; such a path is not guaranteed to be the cheapest path in g.
+func AStarContext(heuristic HeuristicFunc) FindPathContextFunc {
+	return AStarByContext(heuristic, edgeCost)
+}
+
+// AStarByContext returns a FindPathContextFunc that behaves identically to
+// AStarContext, but uses cost to determine the cost of each edge rather than
+// Edge.Cost.
+func AStarByContext(
+	heuristic HeuristicFunc,
+	cost EdgeCostFunc,
+) FindPathContextFunc {
+	return func(ctx context.Context, g *Graph, from Key, to Key) (Path, error) {
+		return astarContext(ctx, g, from.key, to.key, heuristic, cost)
+	}
+}
+
+func astar(
+	src edgeSource,
+	from internal.Key,
+	to internal.Key,
+	heuristic HeuristicFunc,
+	cost EdgeCostFunc,
+) Path {
+	path, _ := astarUntil(nil, src, from, to, heuristic, cost)
+	return path
+}
+
+func astarContext(
+	ctx context.Context,
+	src edgeSource,
+	from internal.Key,
+	to internal.Key,
+	heuristic HeuristicFunc,
+	cost EdgeCostFunc,
+) (Path, error) {
+	path, ok := astarUntil(ctx.Done(), src, from, to, heuristic, cost)
+	if !ok {
+		return path, ctx.Err()
+	}
+
+	return path, nil
+}
+
+// astarUntil performs an A* search, stopping once done is closed. If the search
+// is stopped, astarUntil returns false along with the cheapest path to the
+// vertex to found so far.
+func astarUntil(
+	done <-chan struct{},
+	src edgeSource,
+	from internal.Key,
+	to internal.Key,
+	heuristic HeuristicFunc,
+	cost EdgeCostFunc,
+) (Path, bool) {
+	start, ok := src.vertex(from)
+	if !ok {
+		return Path{}, true
+	}
+
+	target, ok := src.vertex(to)
+	if !ok {
+		return Path{}, true
+	}
+
+	if heuristic == nil {
+		heuristic = func(Vertex, Vertex) int { return 0 }
+	}
+
+	search := astarSearch{
+		src:       src,
+		target:    target,
+		heuristic: heuristic,
+		cost:      cost,
+		costs:     map[internal.Key]int{start.key: 0},
+	}
+
+	return search.run(done, start)
+}
+
+// astarSearch is the state of a single A* search. best holds the cheapest path
+// to target found so far, which is returned if the search is stopped early.
+type astarSearch struct {
+	src       edgeSource
+	target    Vertex
+	heuristic HeuristicFunc
+	cost      EdgeCostFunc
+	costs     map[internal.Key]int
+	best      internal.Path
+}
+
+func (a *astarSearch) run(done <-chan struct{}, start Vertex) (Path, bool) {
+	heap := internal.NewPathHeap(internal.Path{
+		Cost:     0,
+		Estimate: a.heuristic(start, a.target),
+		Vertices: []internal.Key{start.key},
+	})
+
+	for heap.Len() > 0 {
+		if isDone(done) {
+			return newPathFromInternal(a.best), false
+		}
+
+		var (
+			path = heap.Pop()
+			key  = path.Vertices[len(path.Vertices)-1]
+		)
+
+		// A cheaper path to key has been found since this one was pushed.
+		if path.Cost > a.costs[key] {
+			continue
+		}
+
+		if key == a.target.key {
+			return newPathFromInternal(path), true
+		}
+
+		a.src.visitEdges(key, func(edge Edge) bool {
+			if next, ok := a.extend(path, edge); ok {
+				heap.Push(next)
+			}
+
+			return true
+		})
+	}
+
+	return Path{}, true
+}
+
+// extend returns path extended by edge, if doing so yields the cheapest known
+// path to the end of edge.
+func (a *astarSearch) extend(
+	path internal.Path,
+	edge Edge,
+) (internal.Path, bool) {
+	var (
+		step  = a.cost(edge)
+		total = path.Cost + step
+	)
+
+	if best, seen := a.costs[edge.End.key]; seen && best <= total {
+		return internal.Path{}, false
+	}
+	a.costs[edge.End.key] = total
+
+	next := path.Extend(step, edge.End.key)
+	next.Estimate = a.heuristic(edge.End, a.target)
+
+	if edge.End.key == a.target.key {
+		a.best = cheaper(a.best, next)
+	}
+
+	return next, true
+}
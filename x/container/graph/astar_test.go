@@ -0,0 +1,230 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"context"
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+type point struct {
+	x int
+	y int
+}
+
+func manhattan(from graph.Vertex, to graph.Vertex) int {
+	var (
+		a  = from.Value().(point)
+		b  = to.Value().(point)
+		dx = a.x - b.x
+		dy = a.y - b.y
+	)
+
+	if dx < 0 {
+		dx = -dx
+	}
+
+	if dy < 0 {
+		dy = -dy
+	}
+
+	return dx + dy
+}
+
+func newGridGraph(
+	rng *rand.Rand,
+	width int,
+	height int,
+) (*graph.Graph, [][]graph.Key) {
+	var (
+		g    = graph.New()
+		keys = make([][]graph.Key, width)
+	)
+
+	for x := 0; x < width; x++ {
+		keys[x] = make([]graph.Key, height)
+		for y := 0; y < height; y++ {
+			keys[x][y] = g.AddVertex(point{x: x, y: y})
+		}
+	}
+
+	// Every edge costs at least 1, which keeps the Manhattan distance
+	// admissible.
+	connect := func(a graph.Key, b graph.Key) {
+		g.AddEdgeCost(a, b, 1+rng.Intn(5))
+		g.AddEdgeCost(b, a, 1+rng.Intn(5))
+	}
+
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			if x+1 < width {
+				connect(keys[x][y], keys[x+1][y])
+			}
+
+			if y+1 < height {
+				connect(keys[x][y], keys[x][y+1])
+			}
+		}
+	}
+
+	return g, keys
+}
+
+func TestAStarGrid(t *testing.T) {
+	var (
+		rng     = rand.New(rand.NewSource(1))
+		g, keys = newGridGraph(rng, 16, 16)
+		astar   = graph.AStar(manhattan)
+	)
+
+	for i := 0; i < 50; i++ {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var (
+				from     = keys[rng.Intn(16)][rng.Intn(16)]
+				to       = keys[rng.Intn(16)][rng.Intn(16)]
+				expected = g.FindPath(graph.Dijkstra, from, to)
+				actual   = g.FindPath(astar, from, to)
+			)
+
+			require.Equal(t, expected.Cost, actual.Cost)
+			require.Equal(t, from, actual.Vertices[0])
+			require.Equal(t, to, actual.Vertices[len(actual.Vertices)-1])
+			require.Equal(t, actual.Cost, pathCost(t, g, actual))
+		})
+	}
+}
+
+func TestAStarNilHeuristic(t *testing.T) {
+	var (
+		rng   = rand.New(rand.NewSource(2))
+		g     = graph.New()
+		keys  = make([]graph.Key, 32)
+		astar = graph.AStar(nil)
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i)
+	}
+
+	for i := 0; i < 96; i++ {
+		g.AddEdgeCost(
+			keys[rng.Intn(len(keys))],
+			keys[rng.Intn(len(keys))],
+			rng.Intn(10),
+		)
+	}
+
+	for _, from := range keys {
+		for _, to := range keys {
+			var (
+				expected = g.FindPath(graph.Dijkstra, from, to)
+				actual   = g.FindPath(astar, from, to)
+			)
+
+			require.Equal(t, expected.Cost, actual.Cost)
+			require.Equal(
+				t,
+				len(expected.Vertices) == 0,
+				len(actual.Vertices) == 0,
+			)
+		}
+	}
+}
+
+func TestAStarNoPath(t *testing.T) {
+	var (
+		g     = graph.New()
+		k1    = g.AddVertex(point{x: 0, y: 0})
+		k2    = g.AddVertex(point{x: 1, y: 0})
+		k3    = g.AddVertex(point{x: 2, y: 0})
+		astar = graph.AStar(manhattan)
+	)
+
+	g.AddEdge(k1, k2)
+	g.AddEdge(k2, k1)
+
+	require.Equal(t, graph.Path{}, g.FindPath(astar, k1, k3))
+	require.Equal(t, graph.Path{}, g.FindPath(astar, k1, graph.Key{}))
+	require.Equal(t, graph.Path{}, g.FindPath(astar, graph.Key{}, k1))
+	require.Equal(
+		t,
+		graph.Path{Cost: 0, Vertices: []graph.Key{k1}},
+		g.FindPath(astar, k1, k1),
+	)
+}
+
+func pathCost(t *testing.T, g *graph.Graph, path graph.Path) (cost int) {
+	for i := 1; i < len(path.Vertices); i++ {
+		var found bool
+
+		g.VisitEdges(path.Vertices[i-1], func(edge graph.Edge) bool {
+			if edge.End.Key() != path.Vertices[i] {
+				return true
+			}
+
+			cost += edge.Cost
+			found = true
+
+			return false
+		})
+
+		require.True(t, found, "path uses nonexistent edge")
+	}
+
+	return
+}
+
+func TestAStarContext(t *testing.T) {
+	g, keys := newDetourGraph()
+
+	path, err := g.FindPathContext(
+		context.Background(),
+		graph.AStarContext(nil),
+		keys[0],
+		keys[2],
+	)
+	require.NoError(t, err)
+	require.Equal(t, g.FindPath(graph.AStar(nil), keys[0], keys[2]), path)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel as soon as the direct (but expensive) edge to C is discovered,
+	// before the cheaper detour via B is explored.
+	cost := cancelingCost(cancel, func(e graph.Edge) bool {
+		return e.End.Key() == keys[2]
+	})
+	algo := graph.AStarByContext(nil, cost)
+
+	path, err = g.FindPathContext(ctx, algo, keys[0], keys[2])
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 10, path.Cost)
+	require.Equal(t, []graph.Key{keys[0], keys[2]}, path.Vertices)
+
+	path, err = g.FindPathContext(ctx, graph.AStarContext(nil), keys[0], keys[2])
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, graph.Path{}, path)
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// BellmanFord evaluates paths in g spanning from and to and returns the
+// cheapest path possible within the graph. Unlike Dijkstra, BellmanFord
+// supports negative edge costs.
+//
+// If a negative cycle is reachable from the vertex from, no cheapest path
+// exists and an empty path is returned; use BellmanFordE to retrieve the
+// offending cycle.
+func BellmanFord(g *Graph, from Key, to Key) Path {
+	path, err := BellmanFordE(g, from, to)
+	if err != nil {
+		return Path{}
+	}
+
+	return path
+}
+
+// BellmanFordE behaves identically to BellmanFord, except that if a negative
+// cycle is reachable from the vertex from, a *CycleError wrapping
+// ErrNegativeCycle is returned. The error's Cycle starts and ends with the
+// same vertex, and its Cost is the (negative) sum of the cycle's edge costs.
+func BellmanFordE(g *Graph, from Key, to Key) (Path, error) {
+	return bellmanFord(g, from.key, to.key, edgeCost)
+}
+
+// BellmanFordBy returns a FindPathFunc that behaves identically to
+// BellmanFord, but uses cost to determine the cost of each edge rather than
+// Edge.Cost.
+func BellmanFordBy(cost EdgeCostFunc) FindPathFunc {
+	return func(g *Graph, from Key, to Key) Path {
+		path, err := bellmanFord(g, from.key, to.key, cost)
+		if err != nil {
+			return Path{}
+		}
+
+		return path
+	}
+}
+
+func bellmanFord(
+	g *Graph,
+	from internal.Key,
+	to internal.Key,
+	cost EdgeCostFunc,
+) (Path, error) {
+	edges, order, ok := collectEdges(g, from, cost)
+	if !ok {
+		return Path{}, nil
+	}
+
+	var (
+		dist = map[internal.Key]int{from: 0}
+		prev = make(map[internal.Key]costedEdge)
+	)
+
+	// After n-1 rounds of relaxation, every shortest path is known unless a
+	// negative cycle exists.
+	for i := 1; i < order; i++ {
+		if _, changed := relaxEdges(edges, dist, prev); !changed {
+			break
+		}
+	}
+
+	if key, changed := relaxEdges(edges, dist, prev); changed {
+		return Path{}, &CycleError{
+			Err:   ErrNegativeCycle,
+			Cycle: negativeCycle(order, prev, key),
+		}
+	}
+
+	if _, ok := dist[to]; !ok {
+		return Path{}, nil
+	}
+
+	var vertices []internal.Key
+	for key := to; ; key = prev[key].from {
+		vertices = append(vertices, key)
+		if key == from {
+			break
+		}
+	}
+
+	reverseKeys(vertices)
+
+	return newPathFromInternal(internal.Path{
+		Cost:     dist[to],
+		Vertices: vertices,
+	}), nil
+}
+
+type costedEdge struct {
+	from internal.Key
+	to   internal.Key
+	cost int
+}
+
+// collectEdges returns every edge in g, costed by cost, along with the order of
+// g. If the vertex from does not exist in g, collectEdges returns false.
+func collectEdges(
+	g *Graph,
+	from internal.Key,
+	cost EdgeCostFunc,
+) ([]costedEdge, int, bool) {
+	g.mtx.RLock()
+	var (
+		_, ok = g.vertices[from]
+		order = len(g.vertices)
+		all   = make([]Edge, 0, len(g.edges))
+	)
+	for start := range g.edges {
+		all = g.appendEdgesUnsafe(all, start, false)
+	}
+	g.mtx.RUnlock()
+
+	if !ok {
+		return nil, 0, false
+	}
+
+	edges := make([]costedEdge, len(all))
+	for i, edge := range all {
+		edges[i] = costedEdge{
+			from: edge.Start.key,
+			to:   edge.End.key,
+			cost: cost(edge),
+		}
+	}
+
+	return edges, order, true
+}
+
+// relaxEdges performs a single round of Bellman-Ford relaxation, returning
+// whether any distance changed and, if so, the last vertex to change.
+func relaxEdges(
+	edges []costedEdge,
+	dist map[internal.Key]int,
+	prev map[internal.Key]costedEdge,
+) (last internal.Key, changed bool) {
+	for _, edge := range edges {
+		base, ok := dist[edge.from]
+		if !ok {
+			continue
+		}
+
+		if cur, ok := dist[edge.to]; ok && cur <= base+edge.cost {
+			continue
+		}
+
+		dist[edge.to] = base + edge.cost
+		prev[edge.to] = edge
+		last, changed = edge.to, true
+	}
+
+	return
+}
+
+// negativeCycle extracts the negative cycle reachable by walking prev backward
+// from key, in a graph of the given order.
+func negativeCycle(
+	order int,
+	prev map[internal.Key]costedEdge,
+	key internal.Key,
+) Path {
+	// Walking back n times guarantees that key lies on the cycle itself rather
+	// than on a path leading into it.
+	for i := order; i > 0; i-- {
+		key = prev[key].from
+	}
+
+	var (
+		cycle = internal.Path{Vertices: []internal.Key{key}}
+		cur   = key
+	)
+
+	for {
+		edge := prev[cur]
+
+		cycle.Cost += edge.cost
+		cycle.Vertices = append(cycle.Vertices, edge.from)
+
+		if cur = edge.from; cur == key {
+			break
+		}
+	}
+
+	reverseKeys(cycle.Vertices)
+
+	return newPathFromInternal(cycle)
+}
+
+func reverseKeys(keys []internal.Key) {
+	for i, j := 0, len(keys)-1; i < j; i, j = i+1, j-1 {
+		keys[i], keys[j] = keys[j], keys[i]
+	}
+}
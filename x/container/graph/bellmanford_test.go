@@ -0,0 +1,143 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBellmanFordMatchesDijkstra(t *testing.T) {
+	var (
+		rng  = rand.New(rand.NewSource(4))
+		g    = graph.New()
+		keys = make([]graph.Key, 24)
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i)
+	}
+
+	for i := 0; i < 72; i++ {
+		g.AddEdgeCost(
+			keys[rng.Intn(len(keys))],
+			keys[rng.Intn(len(keys))],
+			rng.Intn(10),
+		)
+	}
+
+	for _, from := range keys {
+		for _, to := range keys {
+			var (
+				expected = g.FindPath(graph.Dijkstra, from, to)
+				actual   = g.FindPath(graph.BellmanFord, from, to)
+			)
+
+			require.Equal(t, expected.Cost, actual.Cost)
+			require.Equal(
+				t,
+				len(expected.Vertices) == 0,
+				len(actual.Vertices) == 0,
+			)
+			require.Equal(t, actual.Cost, pathCost(t, g, actual))
+		}
+	}
+}
+
+func TestBellmanFordNegativeCosts(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+		k5 = g.AddVertex(5)
+	)
+
+	g.AddEdgeCost(k1, k2, 4)
+	g.AddEdgeCost(k1, k3, 2)
+	g.AddEdgeCost(k2, k4, -5)
+	g.AddEdgeCost(k3, k4, 1)
+	g.AddEdgeCost(k4, k5, 1)
+
+	path, err := graph.BellmanFordE(g, k1, k5)
+	require.NoError(t, err)
+	require.Equal(t, graph.Path{
+		Cost:     0,
+		Vertices: []graph.Key{k1, k2, k4, k5},
+	}, path)
+
+	path, err = graph.BellmanFordE(g, k5, k1)
+	require.NoError(t, err)
+	require.Equal(t, graph.Path{}, path)
+
+	path, err = graph.BellmanFordE(g, graph.Key{}, k1)
+	require.NoError(t, err)
+	require.Equal(t, graph.Path{}, path)
+
+	require.Equal(
+		t,
+		graph.Path{Cost: 0, Vertices: []graph.Key{k3}},
+		g.FindPath(graph.BellmanFord, k3, k3),
+	)
+}
+
+func TestBellmanFordNegativeCycle(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+		k5 = g.AddVertex(5)
+	)
+
+	g.AddEdgeCost(k1, k2, 1)
+	g.AddEdgeCost(k2, k3, 1)
+	g.AddEdgeCost(k3, k4, -3)
+	g.AddEdgeCost(k4, k2, 1)
+	g.AddEdgeCost(k4, k5, 1)
+
+	path, err := graph.BellmanFordE(g, k1, k5)
+	require.Equal(t, graph.Path{}, path)
+	require.True(t, errors.Is(err, graph.ErrNegativeCycle))
+
+	var cycleErr *graph.CycleError
+	require.True(t, errors.As(err, &cycleErr))
+	require.Equal(t, -1, cycleErr.Cycle.Cost)
+
+	cycle := cycleErr.Cycle.Vertices
+	require.Len(t, cycle, 4)
+	require.Equal(t, cycle[0], cycle[len(cycle)-1])
+	require.ElementsMatch(t, []graph.Key{k2, k3, k4}, cycle[1:])
+	require.Equal(t, cycleErr.Cycle.Cost, pathCost(t, g, cycleErr.Cycle))
+
+	require.Equal(t, graph.Path{}, g.FindPath(graph.BellmanFord, k1, k5))
+
+	// The cycle is not reachable from k5, so paths from it are unaffected.
+	path, err = graph.BellmanFordE(g, k5, k5)
+	require.NoError(t, err)
+	require.Equal(t, graph.Path{Vertices: []graph.Key{k5}}, path)
+}
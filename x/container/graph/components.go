@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"github.com/mway/pkg/x/container/unionfind"
+)
+
+// ConnectedComponents returns the connected components of g. Edge direction is
+// ignored, so for directed graphs the result is the graph's weakly connected
+// components.
+//
+// Components are ordered by their smallest key, and the keys within each
+// component are ordered by key.
+func (g *Graph) ConnectedComponents() [][]Key {
+	var (
+		snap  = g.snapshot()
+		sets  unionfind.UnionFind
+		index = make(map[interface{}]int)
+		comps [][]Key
+	)
+
+	for i := range snap.keys {
+		sets.Add(i)
+	}
+
+	for from, arcs := range snap.arcs {
+		for _, edge := range arcs {
+			sets.Union(from, edge.to)
+		}
+	}
+
+	// Keys are sorted, so components are discovered in order of their
+	// smallest key and populated in key order.
+	for i, key := range snap.keys {
+		root, _ := sets.Find(i)
+
+		idx, ok := index[root]
+		if !ok {
+			idx = len(comps)
+			index[root] = idx
+			comps = append(comps, nil)
+		}
+
+		comps[idx] = append(comps[idx], newKey(key))
+	}
+
+	return comps
+}
+
+// IsConnected returns whether every vertex in g is reachable from every other
+// vertex when edge direction is ignored. Graphs with fewer than two vertices
+// are considered connected.
+func (g *Graph) IsConnected() bool {
+	return len(g.ConnectedComponents()) <= 1
+}
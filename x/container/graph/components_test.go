@@ -0,0 +1,82 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConnectedComponents(t *testing.T) {
+	var (
+		g  = graph.NewUndirected()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+		k5 = g.AddVertex(5)
+		k6 = g.AddVertex(6)
+	)
+
+	require.Len(t, g.ConnectedComponents(), 6)
+	require.False(t, g.IsConnected())
+
+	g.AddEdge(k1, k2)
+	g.AddEdge(k3, k2)
+	g.AddEdge(k4, k5)
+
+	require.Equal(t, [][]graph.Key{
+		{k1, k2, k3},
+		{k4, k5},
+		{k6},
+	}, g.ConnectedComponents())
+	require.False(t, g.IsConnected())
+
+	g.AddEdge(k6, k1)
+	g.AddEdge(k5, k6)
+
+	require.Equal(t, [][]graph.Key{
+		{k1, k2, k3, k4, k5, k6},
+	}, g.ConnectedComponents())
+	require.True(t, g.IsConnected())
+
+	// Filtering may fragment the graph.
+	filtered := g.FilterVertices(func(vertex graph.Vertex) bool {
+		return vertex.Key() != k6
+	})
+
+	require.Equal(t, [][]graph.Key{
+		{k1, k2, k3},
+		{k4, k5},
+	}, filtered.ConnectedComponents())
+	require.False(t, filtered.IsConnected())
+}
+
+func TestConnectedComponentsEmpty(t *testing.T) {
+	g := graph.New()
+	require.Empty(t, g.ConnectedComponents())
+	require.True(t, g.IsConnected())
+
+	g.AddVertex(1)
+	require.True(t, g.IsConnected())
+}
@@ -21,21 +21,103 @@
 package graph
 
 import (
+	"context"
+
 	"github.com/mway/pkg/x/container/graph/internal"
 )
 
 // Dijkstra evaluates paths in g spanning from and to and returns the cheapest
 // path possible within the graph.
 func Dijkstra(g *Graph, from Key, to Key) Path {
+	return newPathFromInternal(dijkstra(g, from.key, to.key, nil, edgeCost))
+}
+
+// DijkstraBy returns a FindPathFunc that behaves identically to Dijkstra, but
+// uses cost to determine the cost of each edge rather than Edge.Cost. Costs
+// must not be negative.
+func DijkstraBy(cost EdgeCostFunc) FindPathFunc {
+	return func(g *Graph, from Key, to Key) Path {
+		return newPathFromInternal(dijkstra(g, from.key, to.key, nil, cost))
+	}
+}
+
+// DijkstraContext behaves identically to Dijkstra, but stops searching once ctx
+// is done. If the search is stopped, ctx.Err() is returned along with the
+// cheapest path spanning from and to found so far, if any; such a path is not
+// guaranteed to be the cheapest path in g.
+func DijkstraContext(
+	ctx context.Context,
+	g *Graph,
+	from Key,
+	to Key,
+) (Path, error) {
+	return DijkstraByContext(edgeCost)(ctx, g, from, to)
+}
+
+// DijkstraByContext returns a FindPathContextFunc that behaves identically to
+// DijkstraContext, but uses cost to determine the cost of each edge rather than
+// Edge.Cost. Costs must not be negative.
+func DijkstraByContext(cost EdgeCostFunc) FindPathContextFunc {
+	return func(ctx context.Context, g *Graph, from Key, to Key) (Path, error) {
+		path, err := dijkstraContext(ctx, g, from.key, to.key, nil, cost)
+		return newPathFromInternal(path), err
+	}
+}
+
+// dijkstra finds the cheapest path spanning from and to, considering only the
+// edges for which filter returns true. A nil filter considers all edges.
+func dijkstra(
+	src edgeSource,
+	from internal.Key,
+	to internal.Key,
+	filter EdgeFilterFunc,
+	cost EdgeCostFunc,
+) internal.Path {
+	path, _ := dijkstraUntil(nil, src, from, to, filter, cost)
+	return path
+}
+
+// dijkstraContext behaves like dijkstra, but stops once ctx is done.
+func dijkstraContext(
+	ctx context.Context,
+	src edgeSource,
+	from internal.Key,
+	to internal.Key,
+	filter EdgeFilterFunc,
+	cost EdgeCostFunc,
+) (internal.Path, error) {
+	path, ok := dijkstraUntil(ctx.Done(), src, from, to, filter, cost)
+	if !ok {
+		return path, ctx.Err()
+	}
+
+	return path, nil
+}
+
+// dijkstraUntil behaves like dijkstra, but stops once done is closed, returning
+// false along with the cheapest path to the vertex to found so far.
+func dijkstraUntil(
+	done <-chan struct{},
+	src edgeSource,
+	from internal.Key,
+	to internal.Key,
+	filter EdgeFilterFunc,
+	cost EdgeCostFunc,
+) (internal.Path, bool) {
 	var (
+		best    internal.Path
 		visited = make(map[internal.Key]struct{})
 		heap    = internal.NewPathHeap(internal.Path{
 			Cost:     0,
-			Vertices: []internal.Key{from.key},
+			Vertices: []internal.Key{from},
 		})
 	)
 
 	for heap.Len() > 0 {
+		if isDone(done) {
+			return best, false
+		}
+
 		var (
 			path = heap.Pop()
 			key  = path.Vertices[len(path.Vertices)-1]
@@ -44,19 +126,54 @@ func Dijkstra(g *Graph, from Key, to Key) Path {
 		if _, seen := visited[key]; seen {
 			continue
 		}
+		visited[key] = struct{}{}
 
-		if key == to.key {
-			return newPathFromInternal(path)
+		if key == to {
+			return path, true
 		}
 
-		g.VisitEdges(newKey(key), func(edge Edge) bool {
-			if _, seen := visited[edge.End.key]; !seen {
-				heap.Push(path.Extend(edge.Cost, edge.End.key))
+		src.visitEdges(key, func(edge Edge) bool {
+			if _, seen := visited[edge.End.key]; seen {
+				return true
+			}
+
+			if filter == nil || filter(edge) {
+				next := path.Extend(cost(edge), edge.End.key)
+				if edge.End.key == to {
+					best = cheaper(best, next)
+				}
+
+				heap.Push(next)
 			}
 
 			return true
 		})
 	}
 
-	return Path{}
+	return internal.Path{}, true
+}
+
+// edgeCost is the default EdgeCostFunc.
+func edgeCost(edge Edge) int {
+	return edge.Cost
+}
+
+// cheaper returns whichever of best and path is cheaper, where an empty best
+// indicates that no path has yet been found.
+func cheaper(best internal.Path, path internal.Path) internal.Path {
+	if len(best.Vertices) == 0 || path.Cost < best.Cost {
+		return path
+	}
+
+	return best
+}
+
+// isDone reports whether done is closed. A nil done is never closed.
+func isDone(done <-chan struct{}) bool {
+	select {
+	case <-done:
+		return true
+	default:
+		return false
+	}
 }
@@ -22,6 +22,7 @@ package graph_test
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/mway/pkg/x/container/graph"
@@ -94,3 +95,78 @@ func TestDijkstraPath(t *testing.T) {
 	// fmt.Println("Graph traversal path:")
 	// fmt.Println(buf.String())
 }
+
+// newDetourGraph returns a graph in which the direct edge from A to C is more
+// expensive than the detour via B, along with the keys of A, B, and C.
+func newDetourGraph() (*graph.Graph, [3]graph.Key) {
+	var (
+		g    = graph.New()
+		keyA = g.AddVertex('A')
+		keyB = g.AddVertex('B')
+		keyC = g.AddVertex('C')
+	)
+
+	g.AddEdgeCost(keyA, keyC, 10)
+	g.AddEdgeCost(keyA, keyB, 1)
+	g.AddEdgeCost(keyB, keyC, 1)
+
+	return g, [3]graph.Key{keyA, keyB, keyC}
+}
+
+// cancelingCost returns an EdgeCostFunc that calls cancel when stop returns
+// true for an edge.
+func cancelingCost(
+	cancel context.CancelFunc,
+	stop func(graph.Edge) bool,
+) graph.EdgeCostFunc {
+	return func(edge graph.Edge) int {
+		if stop(edge) {
+			cancel()
+		}
+
+		return edge.Cost
+	}
+}
+
+func TestDijkstraContext(t *testing.T) {
+	g, keys := newDetourGraph()
+
+	path, err := g.FindPathContext(
+		context.Background(),
+		graph.DijkstraContext,
+		keys[0],
+		keys[2],
+	)
+	require.NoError(t, err)
+	require.Equal(t, g.FindPath(graph.Dijkstra, keys[0], keys[2]), path)
+	require.Equal(t, 2, path.Cost)
+}
+
+func TestDijkstraContextCanceled(t *testing.T) {
+	g, keys := newDetourGraph()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	path, err := graph.DijkstraContext(ctx, g, keys[0], keys[2])
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, graph.Path{}, path)
+}
+
+func TestDijkstraContextPartial(t *testing.T) {
+	g, keys := newDetourGraph()
+
+	// Cancel as soon as the direct (but expensive) edge to C is discovered,
+	// before the cheaper detour via B is explored.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	algo := graph.DijkstraByContext(cancelingCost(cancel, func(e graph.Edge) bool {
+		return e.End.Key() == keys[2]
+	}))
+
+	path, err := g.FindPathContext(ctx, algo, keys[0], keys[2])
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, 10, path.Cost)
+	require.Equal(t, []graph.Key{keys[0], keys[2]}, path.Vertices)
+}
@@ -0,0 +1,184 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// A DOTOption configures the output of WriteDOT.
+type DOTOption func(*dotConfig)
+
+type dotConfig struct {
+	name  string
+	path  map[internal.Key]struct{}
+	steps map[[2]internal.Key]struct{}
+}
+
+// WithDOTName sets the name of the graph in DOT output.
+func WithDOTName(name string) DOTOption {
+	return func(cfg *dotConfig) {
+		cfg.name = name
+	}
+}
+
+// WithDOTPath highlights the vertices and edges of path in DOT output. If there
+// are parallel edges spanning consecutive vertices of path, all of them are
+// highlighted.
+func WithDOTPath(path Path) DOTOption {
+	return func(cfg *dotConfig) {
+		cfg.path = make(map[internal.Key]struct{}, len(path.Vertices))
+		cfg.steps = make(map[[2]internal.Key]struct{}, len(path.Vertices))
+
+		for i, key := range path.Vertices {
+			cfg.path[key.key] = struct{}{}
+			if i > 0 {
+				step := [2]internal.Key{path.Vertices[i-1].key, key.key}
+				cfg.steps[step] = struct{}{}
+			}
+		}
+	}
+}
+
+// WriteDOT writes g to w in the Graphviz DOT language. Vertices are identified
+// by their keys and labeled with their string representations. Edges are
+// labeled with their labels, if any, in which case their costs are written as
+// "cost" attributes, or otherwise with their costs; non-zero weights are
+// written as "weight" attributes. Vertices and edges are written in key order,
+// so the output for a given graph is deterministic.
+//
+// Graphs that are not multigraphs are written as strict graphs. Each edge of an
+// undirected graph is written once.
+func (g *Graph) WriteDOT(w io.Writer, opts ...DOTOption) error {
+	var cfg dotConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	g.mtx.RLock()
+	var (
+		snap       = g.snapshotUnsafe()
+		undirected = g.config.undirected
+		multigraph = g.config.multigraph
+	)
+	g.mtx.RUnlock()
+
+	var (
+		buf bytes.Buffer
+		op  = "->"
+	)
+
+	if !multigraph {
+		buf.WriteString("strict ")
+	}
+
+	if undirected {
+		buf.WriteString("graph ")
+		op = "--"
+	} else {
+		buf.WriteString("digraph ")
+	}
+
+	if cfg.name != "" {
+		buf.WriteString(quoteDOT(cfg.name))
+		buf.WriteRune(' ')
+	}
+
+	buf.WriteString("{\n")
+
+	for i := range snap.vertices {
+		cfg.writeVertex(&buf, &snap.vertices[i])
+	}
+
+	for from, arcs := range snap.arcs {
+		for _, edge := range arcs {
+			if undirected && edge.to < from {
+				continue
+			}
+
+			cfg.writeEdge(&buf, snap.keys[from], snap.keys[edge.to], op, edge.edgeData)
+		}
+	}
+
+	buf.WriteString("}\n")
+
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func (cfg *dotConfig) writeVertex(buf *bytes.Buffer, vertex *Vertex) {
+	fmt.Fprintf(buf, "\t%v [label=%s", vertex.key, quoteDOT(vertex.String()))
+
+	if _, ok := cfg.path[vertex.key]; ok {
+		buf.WriteString(" color=red")
+	}
+
+	buf.WriteString("];\n")
+}
+
+func (cfg *dotConfig) writeEdge(
+	buf *bytes.Buffer,
+	from internal.Key,
+	to internal.Key,
+	op string,
+	data edgeData,
+) {
+	var extra edgeExtra
+	if data.extra != nil {
+		extra = *data.extra
+	}
+
+	fmt.Fprintf(buf, "\t%v %s %v [label=", from, op, to)
+	if extra.label != "" {
+		fmt.Fprintf(buf, `%s cost="%d"`, quoteDOT(extra.label), data.cost)
+	} else {
+		fmt.Fprintf(buf, `"%d"`, data.cost)
+	}
+
+	if extra.weight != 0 {
+		weight := strconv.FormatFloat(extra.weight, 'g', -1, 64)
+		fmt.Fprintf(buf, ` weight="%s"`, weight)
+	}
+
+	_, highlight := cfg.steps[[2]internal.Key{from, to}]
+	if !highlight && op == "--" {
+		_, highlight = cfg.steps[[2]internal.Key{to, from}]
+	}
+
+	if highlight {
+		buf.WriteString(" color=red")
+	}
+
+	buf.WriteString("];\n")
+}
+
+var _dotEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// quoteDOT quotes s as a DOT string.
+func quoteDOT(s string) string {
+	return `"` + _dotEscaper.Replace(s) + `"`
+}
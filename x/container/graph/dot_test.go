@@ -0,0 +1,277 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteDOT(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex("a")
+		k2 = g.AddVertex(`say "hi"`)
+		k3 = g.AddVertex(3)
+	)
+
+	g.AddEdgeCost(k2, k3, 2)
+	g.AddEdgeCost(k1, k3, 5)
+	g.AddEdgeCost(k1, k2, 1)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteDOT(
+		&buf,
+		graph.WithDOTName("test"),
+		graph.WithDOTPath(g.FindPath(graph.Dijkstra, k1, k3)),
+	))
+	require.Equal(t, `strict digraph "test" {
+	1 [label="a" color=red];
+	2 [label="say \"hi\"" color=red];
+	3 [label="3" color=red];
+	1 -> 2 [label="1" color=red];
+	1 -> 3 [label="5"];
+	2 -> 3 [label="2" color=red];
+}
+`, buf.String())
+}
+
+func TestWriteDOTUndirected(t *testing.T) {
+	var (
+		g  = graph.NewUndirectedMultigraph()
+		k1 = g.AddVertex("a")
+		k2 = g.AddVertex("b")
+	)
+
+	g.AddEdgeCost(k2, k1, 2)
+	g.AddEdgeCost(k1, k2, 1)
+	g.AddEdgeCost(k2, k2, 3)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteDOT(
+		&buf,
+		graph.WithDOTPath(graph.Path{Vertices: []graph.Key{k2, k1}}),
+	))
+	require.Equal(t, `graph {
+	1 [label="a" color=red];
+	2 [label="b" color=red];
+	1 -- 2 [label="2" color=red];
+	1 -- 2 [label="1" color=red];
+	2 -- 2 [label="3"];
+}
+`, buf.String())
+}
+
+func TestWriteDOTError(t *testing.T) {
+	g := graph.New()
+	g.AddVertex(1)
+
+	err := errors.New("write failed")
+	require.Equal(t, err, g.WriteDOT(failingWriter{err: err}))
+}
+
+func TestReadDOT(t *testing.T) {
+	src, err := ioutil.ReadFile("testdata/network.dot")
+	require.NoError(t, err)
+
+	g, err := graph.ReadDOT(
+		bytes.NewReader(src),
+		graph.WithVertexIndex(func(value interface{}) string {
+			return fmt.Sprint(value)
+		}),
+	)
+	require.NoError(t, err)
+	require.True(t, g.IsDirected())
+	require.False(t, g.IsMultigraph())
+	require.Equal(t, 4, g.Order())
+
+	require.ElementsMatch(t, []string{
+		"gateway->Core 1(2)",
+		"Core 1->edge1(2)",
+		"gateway->Core 2(5)",
+		"Core 2->edge1(1)",
+		"Core 1->Core 2(1)",
+	}, edgeStrings(g, graph.Root))
+
+	var (
+		gateway = lookup(t, g, "gateway")
+		core2   = lookup(t, g, "Core 2")
+	)
+
+	g.VisitEdges(core2, func(edge graph.Edge) bool {
+		require.Equal(t, "backup", edge.Label())
+		return true
+	})
+	g.VisitEdges(gateway, func(edge graph.Edge) bool {
+		if edge.End.Key() == core2 {
+			require.Equal(t, 0.5, edge.Weight())
+		}
+		return true
+	})
+
+	path := g.FindPath(graph.Dijkstra, gateway, lookup(t, g, "edge1"))
+	require.Equal(t, 4, path.Cost)
+}
+
+func TestReadDOTRoundTrip(t *testing.T) {
+	for _, g := range []*graph.Graph{
+		graph.New(),
+		graph.NewUndirected(),
+		graph.NewMultigraph(),
+		graph.NewUndirectedMultigraph(),
+	} {
+		var (
+			k1 = g.AddVertex("a")
+			k2 = g.AddVertex("b\\n\n")
+			k3 = g.AddVertex("c")
+		)
+
+		g.AddEdgeCost(k1, k2, 1)
+		g.AddEdgeCost(k1, k2, 2)
+		g.AddEdgeCost(k2, k3, -3)
+		g.AddEdgeCost(k3, k3, 4)
+
+		var buf bytes.Buffer
+		require.NoError(t, g.WriteDOT(&buf))
+
+		dup, err := graph.ReadDOT(&buf)
+		require.NoError(t, err)
+		require.Equal(t, g.IsDirected(), dup.IsDirected())
+		require.Equal(t, g.IsMultigraph(), dup.IsMultigraph())
+		require.Equal(t, g.Order(), dup.Order())
+		require.Equal(t, g.Size(), dup.Size())
+		require.ElementsMatch(
+			t,
+			edgeStrings(g, graph.Root),
+			edgeStrings(dup, graph.Root),
+		)
+	}
+}
+
+func TestReadDOTRoundTripAttrs(t *testing.T) {
+	var (
+		g  = graph.NewMultigraph()
+		k1 = g.AddVertex("a")
+		k2 = g.AddVertex("b")
+	)
+
+	g.AddEdgeWith(k1, k2, graph.EdgeAttrs{
+		Cost:   3,
+		Weight: 0.25,
+		Label:  `say "hi"`,
+	})
+	g.AddEdgeWith(k1, k2, graph.EdgeAttrs{Cost: 4, Label: "7"})
+	g.AddEdgeWith(k2, k1, graph.EdgeAttrs{Cost: 5, Weight: 1e-9})
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteDOT(&buf))
+
+	dup, err := graph.ReadDOT(&buf)
+	require.NoError(t, err)
+	require.ElementsMatch(t, edgeAttrStrings(g), edgeAttrStrings(dup))
+}
+
+func edgeAttrStrings(g *graph.Graph) (edges []string) {
+	g.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		edges = append(edges, fmt.Sprintf(
+			"%v->%v(%d, %q, %v)",
+			edge.Start.Value(),
+			edge.End.Value(),
+			edge.Cost,
+			edge.Label(),
+			edge.Weight(),
+		))
+		return true
+	})
+	return
+}
+
+func TestReadDOTErrors(t *testing.T) {
+	cases := []struct {
+		give string
+		line int
+	}{
+		{give: "", line: 1},
+		{give: "tree {}", line: 1},
+		{give: "digraph {\n a -- b\n}", line: 2},
+		{give: "graph {\n a -> b\n}", line: 2},
+		{give: "digraph {\n\n subgraph x { a }\n}", line: 3},
+		{give: "digraph {\n a:n -> b\n}", line: 2},
+		{give: "digraph {\n a -> b [cost=x]\n}", line: 2},
+		{give: "digraph {\n a -> b [weight=x]\n}", line: 2},
+		{give: "digraph {\n a [label]\n}", line: 2},
+		{give: "digraph {\n a -> <b>\n}", line: 2},
+		{give: "digraph {\n a -> \"b\n", line: 3},
+		{give: "digraph {\n /* a -> b\n}", line: 2},
+		{give: "digraph {\n a -> b", line: 2},
+		{give: "digraph {\n a -> b\n} x", line: 3},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.give, func(t *testing.T) {
+			g, err := graph.ReadDOT(strings.NewReader(tt.give))
+			require.Nil(t, g)
+
+			var syntaxErr *graph.SyntaxError
+			require.True(t, errors.As(err, &syntaxErr), "%v", err)
+			require.Equal(t, tt.line, syntaxErr.Line, "%v", err)
+		})
+	}
+
+	err := errors.New("read failed")
+	_, actual := graph.ReadDOT(failingReader{err: err})
+	require.Equal(t, err, actual)
+}
+
+type failingWriter struct {
+	err error
+}
+
+func (w failingWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func lookup(
+	t *testing.T,
+	g interface {
+		Lookup(string) (graph.Key, bool)
+	},
+	index string,
+) graph.Key {
+	key, ok := g.Lookup(index)
+	require.True(t, ok, "no vertex %q", index)
+	return key
+}
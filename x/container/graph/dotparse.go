@@ -0,0 +1,478 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// ReadDOT parses a graph written in the Graphviz DOT language from r, such as
+// one written by WriteDOT, and returns it as a new graph constructed with opts.
+//
+// A "digraph" is parsed as a directed graph, and a "graph" as an undirected
+// graph; unless declared "strict", the result is a multigraph. Vertices are
+// added in the order they first appear. Each vertex's value is its label, if it
+// has one, or otherwise its ID. Each edge's cost is taken from its "cost"
+// attribute or, failing that, its label if the label is an integer, and is
+// otherwise 1. Any other label is used as the edge's label, and a "weight"
+// attribute as its weight.
+//
+// Only a subset of DOT is supported: subgraphs, ports, and HTML strings result
+// in a *SyntaxError, and attributes other than those above are ignored.
+func ReadDOT(r io.Reader, opts ...Option) (*Graph, error) {
+	src, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dotParser{
+		lexer: dotLexer{src: src, line: 1},
+		keys:  make(map[string]Key),
+	}
+
+	p.parse(opts)
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	return p.graph, nil
+}
+
+type dotTokenKind int
+
+const (
+	_dotEOF dotTokenKind = iota
+	_dotID
+	_dotPunct
+)
+
+type dotToken struct {
+	kind   dotTokenKind
+	text   string
+	line   int
+	quoted bool
+}
+
+func (t dotToken) is(punct string) bool {
+	return t.kind == _dotPunct && t.text == punct
+}
+
+func (t dotToken) keyword(word string) bool {
+	return t.kind == _dotID && !t.quoted && strings.EqualFold(t.text, word)
+}
+
+type dotLexer struct {
+	src  []byte
+	pos  int
+	line int
+}
+
+func (l *dotLexer) next() (dotToken, error) {
+	if err := l.skip(); err != nil {
+		return dotToken{}, err
+	}
+
+	tok := dotToken{line: l.line}
+	if l.pos >= len(l.src) {
+		return tok, nil
+	}
+
+	switch c := l.src[l.pos]; {
+	case strings.IndexByte("{}[];,=:", c) >= 0:
+		tok.kind, tok.text = _dotPunct, string(c)
+		l.pos++
+	case l.edgeOp():
+		tok.kind, tok.text = _dotPunct, string(l.src[l.pos:l.pos+2])
+		l.pos += 2
+	case c == '"':
+		return l.quoted()
+	case c == '-' || isDOTIDByte(c):
+		tok.kind, tok.text = _dotID, l.ident()
+	default:
+		return tok, l.errorf("unexpected character %q", c)
+	}
+
+	return tok, nil
+}
+
+// skip skips whitespace and comments.
+func (l *dotLexer) skip() error {
+	for l.pos < len(l.src) {
+		rest := l.src[l.pos:]
+
+		switch {
+		case rest[0] == '\n':
+			l.line++
+			l.pos++
+		case rest[0] == ' ' || rest[0] == '\t' || rest[0] == '\r':
+			l.pos++
+		default:
+			if ok, err := l.comment(); !ok || err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// comment skips a comment, which is either a line comment starting with "//"
+// or "#", or a block comment enclosed by "/*" and "*/". It returns false if
+// the lexer is not positioned at a comment.
+func (l *dotLexer) comment() (bool, error) {
+	rest := string(l.src[l.pos:])
+
+	switch {
+	case rest[0] == '#' || strings.HasPrefix(rest, "//"):
+		for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+			l.pos++
+		}
+	case strings.HasPrefix(rest, "/*"):
+		end := strings.Index(rest, "*/")
+		if end < 0 {
+			return false, l.errorf("unterminated comment")
+		}
+
+		l.line += strings.Count(rest[:end], "\n")
+		l.pos += end + 2
+	default:
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// edgeOp returns whether the lexer is positioned at an edge operator.
+func (l *dotLexer) edgeOp() bool {
+	rest := string(l.src[l.pos:])
+	return strings.HasPrefix(rest, "->") || strings.HasPrefix(rest, "--")
+}
+
+func (l *dotLexer) ident() string {
+	start := l.pos
+	l.pos++
+
+	for l.pos < len(l.src) && isDOTIDByte(l.src[l.pos]) {
+		l.pos++
+	}
+
+	return string(l.src[start:l.pos])
+}
+
+func (l *dotLexer) quoted() (dotToken, error) {
+	var (
+		tok = dotToken{kind: _dotID, line: l.line, quoted: true}
+		buf []byte
+	)
+
+	for l.pos++; l.pos < len(l.src); l.pos++ {
+		c := l.src[l.pos]
+
+		switch {
+		case c == '"':
+			l.pos++
+			tok.text = string(buf)
+			return tok, nil
+		case c == '\\' && l.pos+1 < len(l.src):
+			l.pos++
+			buf = append(buf, unescapeDOT(l.src[l.pos])...)
+		default:
+			buf = append(buf, c)
+		}
+
+		if l.src[l.pos] == '\n' {
+			l.line++
+		}
+	}
+
+	return tok, l.errorf("unterminated string")
+}
+
+func (l *dotLexer) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{
+		Line: l.line,
+		Msg:  fmt.Sprintf(format, args...),
+	}
+}
+
+// unescapeDOT returns the bytes represented by the escape sequence "\c". The
+// sequences written by quoteDOT are unescaped, a backslash followed by a
+// newline is a line continuation, and all other sequences are left as-is.
+func unescapeDOT(c byte) []byte {
+	switch c {
+	case '"', '\\':
+		return []byte{c}
+	case 'n':
+		return []byte{'\n'}
+	case '\n':
+		return nil
+	default:
+		return []byte{'\\', c}
+	}
+}
+
+func isDOTIDByte(c byte) bool {
+	return c == '_' || c == '.' || c >= 0x80 ||
+		('a' <= c && c <= 'z') ||
+		('A' <= c && c <= 'Z') ||
+		('0' <= c && c <= '9')
+}
+
+// dotParser is a recursive descent parser for DOT. Once an error occurs, it is
+// retained and all subsequent tokens are EOF, so that parsing unwinds quickly.
+type dotParser struct {
+	lexer  dotLexer
+	ahead  *dotToken
+	err    error
+	graph  *Graph
+	keys   map[string]Key
+	edgeOp string
+}
+
+func (p *dotParser) parse(opts []Option) {
+	tok := p.next()
+
+	strict := tok.keyword("strict")
+	if strict {
+		tok = p.next()
+	}
+
+	switch {
+	case tok.keyword("digraph"):
+		p.graph, p.edgeOp = NewMultigraph(opts...), "->"
+	case tok.keyword("graph"):
+		p.graph, p.edgeOp = NewUndirectedMultigraph(opts...), "--"
+	default:
+		p.fail(tok, "expected graph or digraph")
+		return
+	}
+
+	p.graph.config.multigraph = !strict
+
+	if p.peek().kind == _dotID {
+		p.next()
+	}
+
+	p.expect("{")
+	p.stmts()
+
+	if tok := p.next(); p.err == nil && tok.kind != _dotEOF {
+		p.fail(tok, "unexpected %q after graph", tok.text)
+	}
+}
+
+func (p *dotParser) stmts() {
+	for p.err == nil {
+		tok := p.next()
+
+		switch {
+		case tok.is("}"):
+			return
+		case tok.is(";"):
+		case tok.keyword("graph"), tok.keyword("node"), tok.keyword("edge"):
+			p.attrs()
+		case tok.keyword("subgraph"), tok.is("{"):
+			p.fail(tok, "subgraphs are not supported")
+		case tok.kind == _dotID:
+			p.stmt(tok)
+		case tok.kind == _dotEOF:
+			p.fail(tok, "unexpected end of input")
+		default:
+			p.fail(tok, "unexpected %q", tok.text)
+		}
+	}
+}
+
+// stmt parses a statement beginning with the ID tok: an attribute assignment,
+// a node statement, or an edge statement.
+func (p *dotParser) stmt(tok dotToken) {
+	if p.peek().is("=") {
+		p.next()
+		p.expectID()
+		return
+	}
+
+	ids := []string{tok.text}
+	for next := p.peek(); next.is("->") || next.is("--"); next = p.peek() {
+		p.next()
+		if next.text != p.edgeOp {
+			p.fail(next, "unexpected %q", next.text)
+			return
+		}
+
+		ids = append(ids, p.expectID())
+	}
+
+	if next := p.peek(); next.is(":") {
+		p.fail(next, "ports are not supported")
+		return
+	}
+
+	attrs := p.attrs()
+	if p.err != nil {
+		return
+	}
+
+	if len(ids) == 1 {
+		p.node(ids[0], attrs)
+		return
+	}
+
+	p.edges(tok, ids, attrs)
+}
+
+// attrs parses zero or more attribute lists.
+func (p *dotParser) attrs() map[string]string {
+	attrs := make(map[string]string)
+
+	for p.err == nil && p.peek().is("[") {
+		p.next()
+
+		for p.err == nil && !p.peek().is("]") {
+			key := p.expectID()
+			p.expect("=")
+			attrs[key] = p.expectID()
+
+			if next := p.peek(); next.is(",") || next.is(";") {
+				p.next()
+			}
+		}
+
+		p.expect("]")
+	}
+
+	return attrs
+}
+
+func (p *dotParser) node(id string, attrs map[string]string) {
+	key := p.vertex(id)
+	if label, ok := attrs["label"]; ok {
+		p.graph.SetVertexValue(key, label)
+	}
+}
+
+func (p *dotParser) edges(tok dotToken, ids []string, attrs map[string]string) {
+	edge, err := dotEdgeAttrs(attrs)
+	if err != nil {
+		p.fail(tok, "%v", err)
+		return
+	}
+
+	for i := 1; i < len(ids); i++ {
+		p.graph.AddEdgeWith(p.vertex(ids[i-1]), p.vertex(ids[i]), edge)
+	}
+}
+
+func (p *dotParser) vertex(id string) Key {
+	key, ok := p.keys[id]
+	if !ok {
+		key = p.graph.AddVertex(id)
+		p.keys[id] = key
+	}
+
+	return key
+}
+
+func (p *dotParser) expect(punct string) {
+	if tok := p.next(); p.err == nil && !tok.is(punct) {
+		p.fail(tok, "expected %q", punct)
+	}
+}
+
+func (p *dotParser) expectID() string {
+	tok := p.next()
+	if p.err == nil && tok.kind != _dotID {
+		p.fail(tok, "expected ID")
+	}
+
+	return tok.text
+}
+
+func (p *dotParser) next() dotToken {
+	if p.ahead != nil {
+		tok := *p.ahead
+		p.ahead = nil
+		return tok
+	}
+
+	if p.err != nil {
+		return dotToken{line: p.lexer.line}
+	}
+
+	tok, err := p.lexer.next()
+	if err != nil {
+		p.err = err
+	}
+
+	return tok
+}
+
+func (p *dotParser) peek() dotToken {
+	if p.ahead == nil {
+		tok := p.next()
+		p.ahead = &tok
+	}
+
+	return *p.ahead
+}
+
+func (p *dotParser) fail(tok dotToken, format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = &SyntaxError{
+			Line: tok.line,
+			Msg:  fmt.Sprintf(format, args...),
+		}
+	}
+}
+
+func dotEdgeAttrs(attrs map[string]string) (EdgeAttrs, error) {
+	var (
+		edge = EdgeAttrs{Cost: 1}
+		err  error
+	)
+
+	cost, hasCost := attrs["cost"]
+	if hasCost {
+		if edge.Cost, err = strconv.Atoi(cost); err != nil {
+			return edge, fmt.Errorf("invalid cost %q", cost)
+		}
+	}
+
+	if label, ok := attrs["label"]; ok {
+		if n, convErr := strconv.Atoi(label); convErr == nil && !hasCost {
+			edge.Cost = n
+		} else {
+			edge.Label = label
+		}
+	}
+
+	if weight, ok := attrs["weight"]; ok {
+		if edge.Weight, err = strconv.ParseFloat(weight, 64); err != nil {
+			return edge, fmt.Errorf("invalid weight %q", weight)
+		}
+	}
+
+	return edge, nil
+}
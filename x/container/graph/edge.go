@@ -20,7 +20,22 @@
 
 package graph
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// EdgeAttrs holds the attributes of an edge. Only Cost is used by the package's
+// pathfinding algorithms; the remaining attributes are metadata carried for
+// callers. In particular, Weight is not a path cost: path costs, including
+// those computed by an EdgeCostFunc, are integers.
+type EdgeAttrs struct {
+	Cost     int
+	Weight   float64
+	Label    string
+	Metadata map[string]interface{}
+}
 
 // Edge is a connection between two incident vertices in a graph. An edge is
 // always directed, but for undirected graphs, can be assumed to be invertable
@@ -29,6 +44,52 @@ type Edge struct {
 	Start Vertex
 	End   Vertex
 	Cost  int
+
+	key   internal.Key
+	extra *edgeExtra
+}
+
+// Attrs returns the attributes of e. The returned metadata is a copy, and may
+// be modified freely.
+func (e *Edge) Attrs() EdgeAttrs {
+	if e == nil {
+		return EdgeAttrs{}
+	}
+
+	data := edgeData{
+		cost:  e.Cost,
+		extra: e.extra,
+	}
+
+	return data.attrs()
+}
+
+// Key returns the key of e. Only edges of multigraphs have non-zero keys.
+func (e *Edge) Key() EdgeKey {
+	if e == nil {
+		return EdgeKey{}
+	}
+
+	return newEdgeKey(e.key)
+}
+
+// Label returns the label of e, if any.
+func (e *Edge) Label() string {
+	if e == nil || e.extra == nil {
+		return ""
+	}
+
+	return e.extra.label
+}
+
+// Metadata returns the metadata value held in e for key, if any.
+func (e *Edge) Metadata(key string) (interface{}, bool) {
+	if e == nil || e.extra == nil {
+		return nil, false
+	}
+
+	value, ok := e.extra.metadata[key]
+	return value, ok
 }
 
 // String returns a string representation of e.
@@ -40,3 +101,116 @@ func (e *Edge) String() string {
 		e.Cost,
 	)
 }
+
+// Weight returns the weight of e, if any. Weight is metadata only, and is not
+// used as a path cost.
+func (e *Edge) Weight() float64 {
+	if e == nil || e.extra == nil {
+		return 0
+	}
+
+	return e.extra.weight
+}
+
+// edgeData is the stored representation of an edge. Edges that only have a
+// cost (i.e., most edges) do not allocate any extra attributes.
+type edgeData struct {
+	key   internal.Key
+	cost  int
+	extra *edgeExtra
+}
+
+func (d edgeData) attrs() EdgeAttrs {
+	attrs := EdgeAttrs{
+		Cost: d.cost,
+	}
+
+	if d.extra != nil {
+		attrs.Weight = d.extra.weight
+		attrs.Label = d.extra.label
+		attrs.Metadata = copyMetadata(d.extra.metadata)
+	}
+
+	return attrs
+}
+
+// edgeList holds the edges spanning a pair of vertices, in the order they were
+// added. Graphs that are not multigraphs hold at most one edge per pair. An
+// edgeList is never modified in place, and thus may be shared between graphs.
+type edgeList []edgeData
+
+// with returns a copy of l that includes data.
+func (l edgeList) with(data edgeData) edgeList {
+	return append(l[:len(l):len(l)], data)
+}
+
+// without returns a copy of l that excludes the edge with the given key.
+func (l edgeList) without(key internal.Key) edgeList {
+	dup := make(edgeList, 0, len(l))
+	for _, data := range l {
+		if data.key != key {
+			dup = append(dup, data)
+		}
+	}
+
+	return dup
+}
+
+// cheapest returns the edge in l with the lowest cost.
+func (l edgeList) cheapest() edgeData {
+	var min edgeData
+	for i, data := range l {
+		if i == 0 || data.cost < min.cost {
+			min = data
+		}
+	}
+
+	return min
+}
+
+// edgeExtra holds an edge's attributes beyond its cost. It is immutable once
+// created, and thus may be shared between edges.
+type edgeExtra struct {
+	weight   float64
+	label    string
+	metadata map[string]interface{}
+}
+
+func newEdgeData(attrs EdgeAttrs) edgeData {
+	data := edgeData{
+		cost: attrs.Cost,
+	}
+
+	if attrs.Weight != 0 || attrs.Label != "" || len(attrs.Metadata) > 0 {
+		data.extra = &edgeExtra{
+			weight:   attrs.Weight,
+			label:    attrs.Label,
+			metadata: copyMetadata(attrs.Metadata),
+		}
+	}
+
+	return data
+}
+
+func newEdge(start Vertex, end Vertex, data edgeData) Edge {
+	return Edge{
+		Start: start,
+		End:   end,
+		Cost:  data.cost,
+		key:   data.key,
+		extra: data.extra,
+	}
+}
+
+func copyMetadata(metadata map[string]interface{}) map[string]interface{} {
+	if len(metadata) == 0 {
+		return nil
+	}
+
+	dup := make(map[string]interface{}, len(metadata))
+	for key, value := range metadata {
+		dup[key] = value
+	}
+
+	return dup
+}
@@ -55,3 +55,148 @@ func TestEdge(t *testing.T) {
 	require.True(t, strings.Contains(formatted, key1.String()))
 	require.True(t, strings.Contains(formatted, key2.String()))
 }
+
+func TestEdgeNil(t *testing.T) {
+	var edge *graph.Edge
+
+	require.NotPanics(t, func() {
+		require.Equal(t, graph.EdgeKey{}, edge.Key())
+		require.Equal(t, graph.EdgeAttrs{}, edge.Attrs())
+		require.Empty(t, edge.Label())
+		require.Zero(t, edge.Weight())
+
+		_, ok := edge.Metadata("x")
+		require.False(t, ok)
+	})
+}
+
+func TestEdgeAttrs(t *testing.T) {
+	var (
+		g        = graph.NewUndirected()
+		k1       = g.AddVertex(1)
+		k2       = g.AddVertex(2)
+		metadata = map[string]interface{}{"protocol": "tcp"}
+	)
+
+	require.False(t, g.AddEdgeWith(k1, graph.Key{}, graph.EdgeAttrs{}))
+	require.True(t, g.AddEdgeWith(k1, k2, graph.EdgeAttrs{
+		Cost:     3,
+		Weight:   1.5,
+		Label:    "uplink",
+		Metadata: metadata,
+	}))
+
+	// Mutating the caller's metadata does not affect the graph.
+	metadata["protocol"] = "udp"
+
+	for _, key := range []graph.Key{k1, k2} {
+		var edge graph.Edge
+		g.VisitEdges(key, func(e graph.Edge) bool {
+			edge = e
+			return false
+		})
+
+		require.Equal(t, 3, edge.Cost)
+		require.Equal(t, 1.5, edge.Weight())
+		require.Equal(t, "uplink", edge.Label())
+
+		value, ok := edge.Metadata("protocol")
+		require.True(t, ok)
+		require.Equal(t, "tcp", value)
+
+		_, ok = edge.Metadata("missing")
+		require.False(t, ok)
+
+		attrs := edge.Attrs()
+		require.Equal(t, graph.EdgeAttrs{
+			Cost:     3,
+			Weight:   1.5,
+			Label:    "uplink",
+			Metadata: map[string]interface{}{"protocol": "tcp"},
+		}, attrs)
+
+		// Nor does mutating the returned attributes.
+		attrs.Metadata["protocol"] = "udp"
+		value, _ = edge.Metadata("protocol")
+		require.Equal(t, "tcp", value)
+	}
+
+	// Attributes survive copies of the graph.
+	dup := g.FilterEdges(func(graph.Edge) bool { return true })
+	dup.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		require.Equal(t, "uplink", edge.Label())
+		return true
+	})
+
+	forest := g.MinimumSpanningForest()
+	forest.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		require.Equal(t, "uplink", edge.Label())
+		return true
+	})
+}
+
+func TestEdgeAttrsDefault(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	g.AddEdgeCost(k1, k2, 2)
+
+	var edge graph.Edge
+	g.VisitEdges(k1, func(e graph.Edge) bool {
+		edge = e
+		return true
+	})
+
+	require.Equal(t, graph.EdgeAttrs{Cost: 2}, edge.Attrs())
+	require.Equal(t, 0.0, edge.Weight())
+	require.Equal(t, "", edge.Label())
+
+	_, ok := edge.Metadata("protocol")
+	require.False(t, ok)
+}
+
+func TestEdgeCostFunc(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(point{x: 0, y: 0})
+		k2 = g.AddVertex(point{x: 1, y: 0})
+		k3 = g.AddVertex(point{x: 2, y: 0})
+	)
+
+	// The direct edge is cheapest by cost, but slowest by latency.
+	g.AddEdgeWith(k1, k3, graph.EdgeAttrs{Cost: 1, Weight: 9.5})
+	g.AddEdgeWith(k1, k2, graph.EdgeAttrs{Cost: 5, Weight: 1.25})
+	g.AddEdgeWith(k2, k3, graph.EdgeAttrs{Cost: 5, Weight: 2.5})
+
+	latency := func(edge graph.Edge) int {
+		return int(edge.Weight() * 100)
+	}
+
+	var (
+		byCost = graph.Path{
+			Cost:     1,
+			Vertices: []graph.Key{k1, k3},
+		}
+		byLatency = graph.Path{
+			Cost:     375,
+			Vertices: []graph.Key{k1, k2, k3},
+		}
+	)
+
+	require.Equal(t, byCost, g.FindPath(graph.Dijkstra, k1, k3))
+	require.Equal(t, byLatency, g.FindPath(graph.DijkstraBy(latency), k1, k3))
+	require.Equal(t, byLatency, g.FindPath(graph.BellmanFordBy(latency), k1, k3))
+	require.Equal(
+		t,
+		byLatency,
+		g.FindPath(graph.AStarBy(manhattan, latency), k1, k3),
+	)
+	require.Equal(
+		t,
+		graph.Paths{byLatency, {Cost: 950, Vertices: []graph.Key{k1, k3}}},
+		g.FindPaths(graph.KShortestBy(2, latency), k1, k3),
+	)
+}
@@ -0,0 +1,74 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrCycle indicates that a cycle was encountered in a graph that is
+	// required to be acyclic.
+	ErrCycle = errors.New("cycle")
+	// ErrDisconnected indicates that a graph is required to be connected, but
+	// is not.
+	ErrDisconnected = errors.New("disconnected")
+	// ErrIncompatible indicates that graphs cannot be combined or compared,
+	// because one is directed and the other is not, or one is a multigraph
+	// and the other is not.
+	ErrIncompatible = errors.New("incompatible graphs")
+	// ErrInvalidKey indicates that a key could not be decoded, or that a
+	// decoded graph uses keys inconsistently.
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrNegativeCycle indicates that a negative-cost cycle was encountered
+	// while searching for a path, and thus no cheapest path exists.
+	ErrNegativeCycle = errors.New("negative cycle")
+)
+
+// A CycleError is returned when a cycle prevents an operation from completing.
+// It wraps the underlying cause (e.g. ErrCycle or ErrNegativeCycle), which can
+// be checked with errors.Is.
+type CycleError struct {
+	Err   error
+	Cycle Path
+}
+
+// Error returns a string representation of e.
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Err, e.Cycle.Vertices)
+}
+
+// Unwrap returns the underlying cause of e.
+func (e *CycleError) Unwrap() error {
+	return e.Err
+}
+
+// A SyntaxError is returned when parsing malformed input, such as by ReadDOT.
+type SyntaxError struct {
+	Line int
+	Msg  string
+}
+
+// Error returns a string representation of e.
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
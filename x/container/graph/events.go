@@ -0,0 +1,176 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"strconv"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// EventType identifies the kind of modification described by an Event.
+type EventType int
+
+// Available event types. Removing a vertex also removes its edges, for each of
+// which an EdgeRemoved event precedes the VertexRemoved event. Replacing the
+// attributes of an existing edge of a graph that is not a multigraph (e.g. via
+// AddEdgeCost) produces an EdgeUpdated event. Replacing the contents of a graph
+// wholesale (e.g. via UnmarshalJSON) produces a single GraphReset event.
+const (
+	VertexAdded EventType = iota + 1
+	VertexRemoved
+	VertexUpdated
+	EdgeAdded
+	EdgeRemoved
+	EdgeUpdated
+	GraphReset
+)
+
+// String returns a string representation of t.
+func (t EventType) String() string {
+	switch t {
+	case VertexAdded:
+		return "VertexAdded"
+	case VertexRemoved:
+		return "VertexRemoved"
+	case VertexUpdated:
+		return "VertexUpdated"
+	case EdgeAdded:
+		return "EdgeAdded"
+	case EdgeRemoved:
+		return "EdgeRemoved"
+	case EdgeUpdated:
+		return "EdgeUpdated"
+	case GraphReset:
+		return "GraphReset"
+	default:
+		return "EventType(" + strconv.Itoa(int(t)) + ")"
+	}
+}
+
+// An Event describes a single modification of a graph.
+type Event struct {
+	// Type is the kind of modification.
+	Type EventType
+	// Vertex is the affected vertex for vertex events. For VertexRemoved
+	// events, it is the vertex as it was before removal; for VertexUpdated
+	// events, it holds the new value.
+	Vertex Vertex
+	// Edge is the affected edge for edge events. For EdgeRemoved events, it is
+	// the edge as it was before removal; for EdgeUpdated events, it holds the
+	// new attributes.
+	Edge Edge
+}
+
+// EventFunc is used by Graph to deliver events to subscribers.
+type EventFunc = func(Event)
+
+// Subscribe registers fn to be called with an Event for each modification of
+// g, and returns a function that cancels the subscription. Subscriptions are
+// not inherited by graphs derived from g (e.g. via FilterVertices or Freeze).
+//
+// Events are delivered synchronously by the goroutine that modified g, once
+// the modification is complete and without g's lock held, so fn may call any
+// method of g, including methods that modify it. The events of a single
+// modification are delivered in order before the modifying method returns;
+// events of concurrent modifications may be delivered concurrently, and in any
+// order. Subscribing or unsubscribing during a modification takes effect for
+// subsequent modifications.
+func (g *Graph) Subscribe(fn EventFunc) (unsubscribe func()) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	g.lastSubscriber++
+
+	id := g.lastSubscriber
+	subs := make([]subscriber, len(g.subscribers), len(g.subscribers)+1)
+	copy(subs, g.subscribers)
+	g.subscribers = append(subs, subscriber{id: id, fn: fn})
+
+	return func() {
+		g.mtx.Lock()
+		defer g.mtx.Unlock()
+
+		subs := make([]subscriber, 0, len(g.subscribers))
+		for _, sub := range g.subscribers {
+			if sub.id != id {
+				subs = append(subs, sub)
+			}
+		}
+
+		g.subscribers = subs
+	}
+}
+
+// A subscriber is a registered EventFunc. Slices of subscribers are never
+// modified in place, and thus may be read after g's lock is released.
+type subscriber struct {
+	id uint64
+	fn EventFunc
+}
+
+// observedUnsafe reports whether g has any subscribers, and thus whether
+// events should be recorded.
+func (g *Graph) observedUnsafe() bool {
+	return len(g.subscribers) > 0
+}
+
+// recordUnsafe records event for delivery once the current modification of g
+// is complete.
+func (g *Graph) recordUnsafe(event Event) {
+	if g.observedUnsafe() {
+		g.events = append(g.events, event)
+	}
+}
+
+// recordEdgeUnsafe records an event of type typ for the edge spanning from and
+// to with data. The edge is only constructed if g has subscribers.
+func (g *Graph) recordEdgeUnsafe(
+	typ EventType,
+	from internal.Key,
+	to internal.Key,
+	data edgeData,
+) {
+	if g.observedUnsafe() {
+		g.recordUnsafe(Event{
+			Type: typ,
+			Edge: g.newEdgeUnsafe(from, to, data),
+		})
+	}
+}
+
+// unlockAndPublish releases g's write lock, and then delivers the events
+// recorded by the completed modification to g's subscribers.
+func (g *Graph) unlockAndPublish() {
+	var (
+		events = g.events
+		subs   = g.subscribers
+	)
+
+	g.events = nil
+	g.mtx.Unlock()
+
+	for _, event := range events {
+		for _, sub := range subs {
+			sub.fn(event)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphSubscribe(t *testing.T) {
+	var (
+		g      = graph.New()
+		events []string
+	)
+
+	unsubscribe := g.Subscribe(func(event graph.Event) {
+		events = append(events, eventString(event))
+	})
+
+	var (
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	require.True(t, g.AddEdgeCost(k1, k2, 3))
+	require.True(t, g.AddEdgeCost(k1, k2, 4))
+	require.True(t, g.AddEdge(k2, k1))
+	require.True(t, g.SetVertexValue(k2, 5))
+	g.DeleteEdge(k1, k2)
+	g.DeleteEdge(k1, k2)
+	g.DeleteVertex(k1)
+
+	require.Equal(t, []string{
+		"VertexAdded 1",
+		"VertexAdded 2",
+		"EdgeAdded 1->2(3)",
+		"EdgeUpdated 1->2(4)",
+		"EdgeAdded 2->1(1)",
+		"VertexUpdated 5",
+		"EdgeRemoved 1->5(4)",
+		"EdgeRemoved 5->1(1)",
+		"VertexRemoved 1",
+	}, events)
+
+	unsubscribe()
+	unsubscribe()
+
+	g.AddVertex(6)
+	require.Len(t, events, 9)
+}
+
+func TestGraphSubscribeUndirectedMultigraph(t *testing.T) {
+	var (
+		g      = graph.NewUndirectedMultigraph()
+		k1     = g.AddVertex(1)
+		k2     = g.AddVertex(2)
+		k3     = g.AddVertex(3)
+		events []string
+	)
+
+	e1, _ := g.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 1})
+	g.AddEdgeKey(k2, k1, graph.EdgeAttrs{Cost: 2})
+	g.AddEdgeKey(k2, k3, graph.EdgeAttrs{Cost: 3})
+	g.AddEdgeKey(k3, k3, graph.EdgeAttrs{Cost: 4})
+
+	g.Subscribe(func(event graph.Event) {
+		events = append(events, eventString(event))
+	})
+
+	g.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 5})
+	g.DeleteEdgeKey(e1)
+	g.DeleteEdgeKey(e1)
+	g.DeleteVertex(k3)
+	g.DeleteEdge(k2, k1)
+
+	require.Equal(t, []string{
+		"EdgeAdded 1->2(5)",
+		"EdgeRemoved 1->2(1)",
+		"EdgeRemoved 3->2(3)",
+		"EdgeRemoved 3->3(4)",
+		"VertexRemoved 3",
+		"EdgeRemoved 2->1(2)",
+		"EdgeRemoved 2->1(5)",
+	}, sortRemovals(events))
+}
+
+func TestGraphSubscribeMutate(t *testing.T) {
+	var (
+		g      = graph.New()
+		root   = g.AddVertex(0)
+		events []string
+	)
+
+	// Each added vertex is linked to the root, from within the subscriber.
+	g.Subscribe(func(event graph.Event) {
+		events = append(events, eventString(event))
+
+		if event.Type == graph.VertexAdded {
+			g.AddEdge(root, event.Vertex.Key())
+		}
+	})
+
+	g.AddVertex(1)
+	g.AddVertex(2)
+
+	require.Equal(t, 2, g.Size())
+	require.Equal(t, []string{
+		"VertexAdded 1",
+		"EdgeAdded 0->1(1)",
+		"VertexAdded 2",
+		"EdgeAdded 0->2(1)",
+	}, events)
+}
+
+func TestGraphSubscribeReset(t *testing.T) {
+	var (
+		g      = graph.New()
+		events []graph.Event
+	)
+
+	g.AddVertex(1)
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	g.Subscribe(func(event graph.Event) {
+		events = append(events, event)
+	})
+
+	require.NoError(t, json.Unmarshal(data, g))
+	require.Equal(t, []graph.Event{{Type: graph.GraphReset}}, events)
+}
+
+func TestEventTypeString(t *testing.T) {
+	require.Equal(t, "VertexAdded", graph.VertexAdded.String())
+	require.Equal(t, "GraphReset", graph.GraphReset.String())
+	require.Equal(t, "EventType(0)", graph.EventType(0).String())
+}
+
+func eventString(event graph.Event) string {
+	switch event.Type {
+	case graph.VertexAdded, graph.VertexRemoved, graph.VertexUpdated:
+		return fmt.Sprintf("%v %v", event.Type, event.Vertex.Value())
+	default:
+		return fmt.Sprintf(
+			"%v %v->%v(%d)",
+			event.Type,
+			event.Edge.Start.Value(),
+			event.Edge.End.Value(),
+			event.Edge.Cost,
+		)
+	}
+}
+
+// sortRemovals sorts each run of consecutive EdgeRemoved events, whose order
+// within a single modification is unspecified.
+func sortRemovals(events []string) []string {
+	const prefix = "EdgeRemoved"
+
+	for i := 0; i < len(events); {
+		j := i
+		for j < len(events) && len(events[j]) > len(prefix) &&
+			events[j][:len(prefix)] == prefix {
+			j++
+		}
+
+		if j == i {
+			i++
+			continue
+		}
+
+		sort.Strings(events[i:j])
+		i = j
+	}
+
+	return events
+}
@@ -0,0 +1,230 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+const _maxInt = int(^uint(0) >> 1)
+
+// A Flow is the result of a maximum flow computation between two vertices.
+type Flow struct {
+	// Value is the total flow from the source to the sink, which is equal to
+	// the total capacity of Cut.
+	Value int
+	// Edges contains every edge carrying a positive flow.
+	Edges []FlowEdge
+	// Cut contains the edges of a minimum cut separating the source from the
+	// sink: removing them leaves the sink unreachable from the source, and no
+	// cheaper (by total capacity) such set of edges exists.
+	Cut []Edge
+
+	flows map[[2]internal.Key]int
+}
+
+// A FlowEdge is an edge and the flow assigned to it.
+type FlowEdge struct {
+	Edge Edge
+	Flow int
+}
+
+// EdgeFlow returns the flow assigned to the edge spanning from and to. If there
+// are parallel edges spanning from and to, their flows are summed.
+func (f *Flow) EdgeFlow(from Key, to Key) int {
+	return f.flows[[2]internal.Key{from.key, to.key}]
+}
+
+// MaxFlow computes the maximum flow from source to sink using Dinic's
+// algorithm, treating each edge's cost as its capacity. Edges with negative
+// costs are treated as having no capacity. If either vertex does not exist, or
+// if source and sink are the same vertex, the resulting flow is empty.
+func (g *Graph) MaxFlow(source Key, sink Key) *Flow {
+	var (
+		snap   = g.snapshot()
+		s, ok1 = snap.index[source.key]
+		t, ok2 = snap.index[sink.key]
+	)
+
+	if !ok1 || !ok2 || s == t {
+		return &Flow{}
+	}
+
+	network := newFlowNetwork(snap)
+	value := network.maxFlow(s, t)
+
+	return network.result(value, s)
+}
+
+type flowArc struct {
+	to       int
+	rev      int // index of the paired arc in adj[to]
+	residual int
+	capacity int // capacity of the original edge, or -1 for reverse arcs
+	data     edgeData
+}
+
+type flowNetwork struct {
+	snap  *snapshot
+	adj   [][]flowArc
+	level []int
+	iter  []int
+}
+
+func newFlowNetwork(snap *snapshot) *flowNetwork {
+	n := &flowNetwork{
+		snap:  snap,
+		adj:   make([][]flowArc, len(snap.keys)),
+		level: make([]int, len(snap.keys)),
+		iter:  make([]int, len(snap.keys)),
+	}
+
+	for from, arcs := range snap.arcs {
+		for _, edge := range arcs {
+			capacity := edge.cost
+			if capacity < 0 {
+				capacity = 0
+			}
+
+			n.adj[from] = append(n.adj[from], flowArc{
+				to:       edge.to,
+				rev:      len(n.adj[edge.to]),
+				residual: capacity,
+				capacity: capacity,
+				data:     edge.edgeData,
+			})
+			n.adj[edge.to] = append(n.adj[edge.to], flowArc{
+				to:       from,
+				rev:      len(n.adj[from]) - 1,
+				residual: 0,
+				capacity: -1,
+			})
+		}
+	}
+
+	return n
+}
+
+func (n *flowNetwork) maxFlow(s int, t int) (total int) {
+	for n.levels(s); n.level[t] >= 0; n.levels(s) {
+		for i := range n.iter {
+			n.iter[i] = 0
+		}
+
+		for {
+			pushed := n.augment(s, t, _maxInt)
+			if pushed == 0 {
+				break
+			}
+
+			total += pushed
+		}
+	}
+
+	return total
+}
+
+// levels assigns BFS levels in the residual network from s. Vertices that are
+// unreachable from s are assigned a level of -1.
+func (n *flowNetwork) levels(s int) {
+	for i := range n.level {
+		n.level[i] = -1
+	}
+
+	n.level[s] = 0
+	queue := []int{s}
+
+	for len(queue) > 0 {
+		u := queue[0]
+		queue = queue[1:]
+
+		for _, a := range n.adj[u] {
+			if a.residual > 0 && n.level[a.to] < 0 {
+				n.level[a.to] = n.level[u] + 1
+				queue = append(queue, a.to)
+			}
+		}
+	}
+}
+
+// augment finds an augmenting path from u to t along increasing levels and
+// pushes at most limit units of flow along it, returning the amount pushed.
+func (n *flowNetwork) augment(u int, t int, limit int) int {
+	if u == t {
+		return limit
+	}
+
+	for ; n.iter[u] < len(n.adj[u]); n.iter[u]++ {
+		a := &n.adj[u][n.iter[u]]
+		if a.residual <= 0 || n.level[a.to] != n.level[u]+1 {
+			continue
+		}
+
+		want := a.residual
+		if limit < want {
+			want = limit
+		}
+
+		if pushed := n.augment(a.to, t, want); pushed > 0 {
+			a.residual -= pushed
+			n.adj[a.to][a.rev].residual += pushed
+			return pushed
+		}
+	}
+
+	return 0
+}
+
+func (n *flowNetwork) result(value int, s int) *Flow {
+	flow := &Flow{
+		Value: value,
+		flows: make(map[[2]internal.Key]int),
+	}
+
+	// After the final BFS, the level of every vertex still reachable from s in
+	// the residual network is non-negative; those vertices form the source
+	// side of a minimum cut.
+	n.levels(s)
+
+	for u, arcs := range n.adj {
+		for _, a := range arcs {
+			if a.capacity < 0 {
+				continue
+			}
+
+			data := a.data
+			data.cost = a.capacity
+
+			edge := newEdge(n.snap.vertices[u], n.snap.vertices[a.to], data)
+
+			if used := a.capacity - a.residual; used > 0 {
+				flow.Edges = append(flow.Edges, FlowEdge{Edge: edge, Flow: used})
+				flow.flows[[2]internal.Key{n.snap.keys[u], n.snap.keys[a.to]}] += used
+			}
+
+			if n.level[u] >= 0 && n.level[a.to] < 0 && a.capacity > 0 {
+				flow.Cut = append(flow.Cut, edge)
+			}
+		}
+	}
+
+	return flow
+}
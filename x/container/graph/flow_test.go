@@ -0,0 +1,199 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxFlow(t *testing.T) {
+	var (
+		g      = graph.New()
+		source = g.AddVertex("s")
+		v1     = g.AddVertex("v1")
+		v2     = g.AddVertex("v2")
+		v3     = g.AddVertex("v3")
+		v4     = g.AddVertex("v4")
+		sink   = g.AddVertex("t")
+	)
+
+	g.AddEdgeCost(source, v1, 16)
+	g.AddEdgeCost(source, v2, 13)
+	g.AddEdgeCost(v1, v3, 12)
+	g.AddEdgeCost(v2, v1, 4)
+	g.AddEdgeCost(v2, v4, 14)
+	g.AddEdgeCost(v3, v2, 9)
+	g.AddEdgeCost(v3, sink, 20)
+	g.AddEdgeCost(v4, v3, 7)
+	g.AddEdgeCost(v4, sink, 4)
+
+	flow := g.MaxFlow(source, sink)
+	require.Equal(t, 23, flow.Value)
+	require.Equal(t, 12, flow.EdgeFlow(v1, v3))
+	require.Equal(t, 4, flow.EdgeFlow(v4, sink))
+	require.Equal(t, 0, flow.EdgeFlow(sink, source))
+
+	var cut []string
+	for _, edge := range flow.Cut {
+		cut = append(cut, edge.String())
+	}
+
+	require.ElementsMatch(t, []string{
+		(&graph.Edge{Start: get(t, g, v1), End: get(t, g, v3), Cost: 12}).String(),
+		(&graph.Edge{Start: get(t, g, v4), End: get(t, g, v3), Cost: 7}).String(),
+		(&graph.Edge{Start: get(t, g, v4), End: get(t, g, sink), Cost: 4}).String(),
+	}, cut)
+
+	requireValidFlow(t, g, source, sink, flow)
+}
+
+func TestMaxFlowEmpty(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	g.AddEdgeCost(k1, k2, -1)
+
+	require.Equal(t, &graph.Flow{}, g.MaxFlow(k1, graph.Key{}))
+	require.Equal(t, &graph.Flow{}, g.MaxFlow(k1, k1))
+
+	flow := g.MaxFlow(k1, k2)
+	require.Equal(t, 0, flow.Value)
+	require.Empty(t, flow.Edges)
+	require.Empty(t, flow.Cut)
+}
+
+func TestMaxFlowMultigraph(t *testing.T) {
+	var (
+		g      = graph.NewMultigraph()
+		source = g.AddVertex("s")
+		sink   = g.AddVertex("t")
+	)
+
+	g.AddEdgeCost(source, sink, 3)
+	g.AddEdgeCost(source, sink, 4)
+
+	flow := g.MaxFlow(source, sink)
+	require.Equal(t, 7, flow.Value)
+	require.Equal(t, 7, flow.EdgeFlow(source, sink))
+	require.Len(t, flow.Edges, 2)
+	require.Len(t, flow.Cut, 2)
+	require.NotEqual(t, flow.Cut[0].Key(), flow.Cut[1].Key())
+}
+
+func TestMaxFlowRandom(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var (
+				rng  = rand.New(rand.NewSource(int64(i)))
+				g    = graph.New()
+				keys = make([]graph.Key, 12)
+			)
+
+			for i := range keys {
+				keys[i] = g.AddVertex(i)
+			}
+
+			for i := 0; i < 40; i++ {
+				g.AddEdgeCost(
+					keys[rng.Intn(len(keys))],
+					keys[rng.Intn(len(keys))],
+					rng.Intn(20),
+				)
+			}
+
+			var (
+				source = keys[0]
+				sink   = keys[len(keys)-1]
+				flow   = g.MaxFlow(source, sink)
+			)
+
+			requireValidFlow(t, g, source, sink, flow)
+		})
+	}
+}
+
+// requireValidFlow checks that flow respects edge capacities and conservation,
+// and that its cut is a minimum cut: it separates source from sink, and its
+// capacity equals the flow's value.
+func requireValidFlow(
+	t *testing.T,
+	g *graph.Graph,
+	source graph.Key,
+	sink graph.Key,
+	flow *graph.Flow,
+) {
+	balance := make(map[graph.Key]int)
+
+	for _, edge := range flow.Edges {
+		var (
+			start = edge.Edge.Start.Key()
+			end   = edge.Edge.End.Key()
+		)
+
+		require.Greater(t, edge.Flow, 0)
+		require.LessOrEqual(t, edge.Flow, edge.Edge.Cost)
+		require.Equal(t, edge.Flow, flow.EdgeFlow(start, end))
+
+		balance[start] -= edge.Flow
+		balance[end] += edge.Flow
+	}
+
+	for key, net := range balance {
+		switch key {
+		case source:
+			require.Equal(t, -flow.Value, net)
+		case sink:
+			require.Equal(t, flow.Value, net)
+		default:
+			require.Equal(t, 0, net)
+		}
+	}
+
+	var (
+		capacity int
+		cut      = make(map[[2]graph.Key]struct{})
+	)
+
+	for _, edge := range flow.Cut {
+		capacity += edge.Cost
+		cut[[2]graph.Key{edge.Start.Key(), edge.End.Key()}] = struct{}{}
+	}
+
+	require.Equal(t, flow.Value, capacity)
+
+	residual := g.FilterEdges(func(edge graph.Edge) bool {
+		_, ok := cut[[2]graph.Key{edge.Start.Key(), edge.End.Key()}]
+		return !ok && edge.Cost > 0
+	})
+
+	residual.VisitVertices(source, func(vertex graph.Vertex) bool {
+		require.NotEqual(t, sink, vertex.Key())
+		return true
+	})
+}
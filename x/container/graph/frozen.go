@@ -0,0 +1,473 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"sort"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// An edgeSource provides vertices and their outgoing edges to the pathfinding
+// algorithms shared by Graph and Frozen.
+type edgeSource interface {
+	vertex(key internal.Key) (Vertex, bool)
+	visitEdges(key internal.Key, fn EdgeVisitorFunc)
+	cheapestEdge(
+		from internal.Key,
+		to internal.Key,
+		cost EdgeCostFunc,
+	) (Edge, bool)
+}
+
+var (
+	_ edgeSource = (*Graph)(nil)
+	_ edgeSource = (*Frozen)(nil)
+)
+
+// A Frozen graph is an immutable snapshot of a Graph, created by Graph.Freeze.
+// Its edges are stored in compressed sparse row form, and because it cannot
+// change, its methods are safe for concurrent use without any locking. This
+// makes Frozen graphs well suited to read-heavy workloads, such as serving many
+// concurrent path queries.
+//
+// Unlike Graph, Frozen visits vertices and edges in key order.
+type Frozen struct {
+	keys       []internal.Key
+	index      map[internal.Key]int
+	vertices   []Vertex
+	lookup     map[string]internal.Key
+	out        csr
+	in         csr
+	size       int
+	undirected bool
+	multigraph bool
+}
+
+// Freeze returns an immutable snapshot of g. Subsequent changes to g are not
+// reflected in the snapshot.
+func (g *Graph) Freeze() *Frozen {
+	g.mtx.RLock()
+	var (
+		snap = g.snapshotUnsafe()
+		f    = &Frozen{
+			keys:       snap.keys,
+			index:      snap.index,
+			vertices:   snap.vertices,
+			lookup:     make(map[string]internal.Key, len(g.index)),
+			size:       g.size,
+			undirected: g.config.undirected,
+			multigraph: g.config.multigraph,
+		}
+	)
+
+	for index, owners := range g.index {
+		f.lookup[index] = owners.owner()
+	}
+	g.mtx.RUnlock()
+
+	f.out = newCSR(snap.arcs)
+	f.in = f.out.transpose()
+
+	return f
+}
+
+// AStar uses the A* search algorithm to find the cheapest path spanning from
+// and to. See AStar (the function) for details.
+func (f *Frozen) AStar(heuristic HeuristicFunc, from Key, to Key) Path {
+	return astar(f, from.key, to.key, heuristic, edgeCost)
+}
+
+// AStarBy behaves identically to AStar, but uses cost to determine the cost of
+// each edge. See AStarBy (the function) for details.
+func (f *Frozen) AStarBy(
+	heuristic HeuristicFunc,
+	cost EdgeCostFunc,
+	from Key,
+	to Key,
+) Path {
+	return astar(f, from.key, to.key, heuristic, cost)
+}
+
+// Dijkstra finds the cheapest path spanning from and to. See Dijkstra (the
+// function) for details.
+func (f *Frozen) Dijkstra(from Key, to Key) Path {
+	return newPathFromInternal(dijkstra(f, from.key, to.key, nil, edgeCost))
+}
+
+// DijkstraBy behaves identically to Dijkstra, but uses cost to determine the
+// cost of each edge. See DijkstraBy (the function) for details.
+func (f *Frozen) DijkstraBy(cost EdgeCostFunc, from Key, to Key) Path {
+	return newPathFromInternal(dijkstra(f, from.key, to.key, nil, cost))
+}
+
+// EdgeCost returns the cost of the edge spanning from and to, if such an edge
+// exists. See Graph.EdgeCost for details.
+func (f *Frozen) EdgeCost(from Key, to Key) (int, bool) {
+	edge, ok := f.cheapestEdge(from.key, to.key, edgeCost)
+	return edge.Cost, ok
+}
+
+// Get gets the Vertex represented by key, if it exists.
+func (f *Frozen) Get(key Key) (Vertex, bool) {
+	return f.vertex(key.key)
+}
+
+// HasEdge returns whether an edge spanning from and to exists.
+func (f *Frozen) HasEdge(from Key, to Key) bool {
+	i, ok1 := f.index[from.key]
+	j, ok2 := f.index[to.key]
+	if !ok1 || !ok2 {
+		return false
+	}
+
+	lo, hi := f.out.span(i, j)
+	return lo < hi
+}
+
+// InDegree returns the number of edges ending at the vertex key. See
+// Graph.InDegree for details.
+func (f *Frozen) InDegree(key Key) int {
+	i, ok := f.index[key.key]
+	if !ok {
+		return 0
+	}
+
+	return f.in.degree(i)
+}
+
+// IsDirected returns whether f is a directed graph.
+func (f *Frozen) IsDirected() bool {
+	return !f.undirected
+}
+
+// IsMultigraph returns whether f is a multigraph.
+func (f *Frozen) IsMultigraph() bool {
+	return f.multigraph
+}
+
+// KShortest uses Yen's algorithm to find up to k loopless paths spanning from
+// and to. See KShortest (the function) for details.
+func (f *Frozen) KShortest(k int, from Key, to Key) Paths {
+	return kShortest(f, from.key, to.key, k, edgeCost)
+}
+
+// KShortestBy behaves identically to KShortest, but uses cost to determine the
+// cost of each edge. See KShortestBy (the function) for details.
+func (f *Frozen) KShortestBy(
+	k int,
+	cost EdgeCostFunc,
+	from Key,
+	to Key,
+) Paths {
+	return kShortest(f, from.key, to.key, k, cost)
+}
+
+// Lookup returns the key of the vertex whose value has the given index key.
+// See Graph.Lookup for details.
+func (f *Frozen) Lookup(index string) (Key, bool) {
+	key, ok := f.lookup[index]
+	if !ok {
+		return _zeroKey, false
+	}
+
+	return newKey(key), true
+}
+
+// Order returns the order of the graph (the number of vertices).
+func (f *Frozen) Order() int {
+	return len(f.keys)
+}
+
+// OutDegree returns the number of edges starting at the vertex key. See
+// Graph.OutDegree for details.
+func (f *Frozen) OutDegree(key Key) int {
+	i, ok := f.index[key.key]
+	if !ok {
+		return 0
+	}
+
+	return f.out.degree(i)
+}
+
+// Predecessors returns the keys of the vertices with an edge ending at the
+// vertex key, in key order.
+func (f *Frozen) Predecessors(key Key) []Key {
+	return f.neighbors(&f.in, key.key)
+}
+
+// ShortestPathTree computes the cheapest paths from the vertex from to every
+// vertex reachable from it. See ShortestPathTree (the function) for details.
+func (f *Frozen) ShortestPathTree(
+	from Key,
+	opts ...TreeOption,
+) *PathTree {
+	tree, _ := shortestPathTree(nil, f, from.key, opts)
+	return tree
+}
+
+// Size returns the size of the graph (the number of edges). See Graph.Size for
+// details.
+func (f *Frozen) Size() int {
+	return f.size
+}
+
+// Successors returns the keys of the vertices with an edge starting at the
+// vertex key, in key order.
+func (f *Frozen) Successors(key Key) []Key {
+	return f.neighbors(&f.out, key.key)
+}
+
+// Traverse uses fn to visit each vertex reachable from the vertex key. See
+// Graph.Traverse for details.
+func (f *Frozen) Traverse(
+	key Key,
+	order TraversalOrder,
+	direction Direction,
+	fn TraversalVisitorFunc,
+) {
+	adj := &f.out
+	if direction == Incoming {
+		adj = &f.in
+	}
+
+	t := traversal{
+		vertex: f.vertex,
+		adjacent: func(key internal.Key) []internal.Key {
+			return adj.neighbors(f.index[key], f.keys)
+		},
+		visited: make(map[internal.Key]struct{}),
+		fn:      fn,
+	}
+
+	roots := []internal.Key{key.key}
+	if key == Root {
+		roots = f.keys
+	}
+
+	t.run(roots, order)
+}
+
+// VisitEdges uses fn to visit each edge, starting at the vertex key. See
+// Graph.VisitEdges for details.
+func (f *Frozen) VisitEdges(key Key, fn EdgeVisitorFunc) {
+	if key != Root {
+		f.visitEdges(key.key, fn)
+		return
+	}
+
+	for i := range f.keys {
+		if !f.visitRow(i, f.undirected, fn) {
+			return
+		}
+	}
+}
+
+// VisitVertices uses fn to visit each vertex, starting at the vertex key, in
+// breadth-first order. See Graph.VisitVertices for details.
+func (f *Frozen) VisitVertices(key Key, fn VertexVisitorFunc) {
+	f.Traverse(key, LevelOrder, Outgoing, func(visit Visit) bool {
+		return fn(visit.Vertex)
+	})
+}
+
+func (f *Frozen) cheapestEdge(
+	from internal.Key,
+	to internal.Key,
+	cost EdgeCostFunc,
+) (Edge, bool) {
+	i, ok1 := f.index[from]
+	j, ok2 := f.index[to]
+	if !ok1 || !ok2 {
+		return Edge{}, false
+	}
+
+	var (
+		min    Edge
+		lo, hi = f.out.span(i, j)
+	)
+
+	for k := lo; k < hi; k++ {
+		edge := newEdge(f.vertices[i], f.vertices[j], f.out.data[k])
+		if k == lo || cost(edge) < cost(min) {
+			min = edge
+		}
+	}
+
+	return min, lo < hi
+}
+
+func (f *Frozen) neighbors(adj *csr, key internal.Key) []Key {
+	i, ok := f.index[key]
+	if !ok {
+		return nil
+	}
+
+	keys := adj.neighbors(i, f.keys)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	dup := make([]Key, len(keys))
+	for k, key := range keys {
+		dup[k] = newKey(key)
+	}
+
+	return dup
+}
+
+func (f *Frozen) vertex(key internal.Key) (Vertex, bool) {
+	i, ok := f.index[key]
+	if !ok {
+		return Vertex{}, false
+	}
+
+	return f.vertices[i], true
+}
+
+func (f *Frozen) visitEdges(key internal.Key, fn EdgeVisitorFunc) {
+	if i, ok := f.index[key]; ok {
+		f.visitRow(i, false, fn)
+	}
+}
+
+// visitRow visits the edges starting at the ith vertex with fn, returning false
+// if fn stopped visiting. If dedupe is true, edges ending at a lower index than
+// i are skipped, so that undirected edges are only visited once.
+func (f *Frozen) visitRow(i int, dedupe bool, fn EdgeVisitorFunc) bool {
+	lo, hi := f.out.row(i)
+
+	for k := lo; k < hi; k++ {
+		to := f.out.targets[k]
+		if dedupe && to < i {
+			continue
+		}
+
+		if !fn(newEdge(f.vertices[i], f.vertices[to], f.out.data[k])) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// A csr is an adjacency structure in compressed sparse row form: the edges of
+// the ith vertex end at the vertices indexed by targets[offsets[i]:
+// offsets[i+1]], in ascending order, and have the corresponding data.
+type csr struct {
+	offsets []int
+	targets []int
+	data    []edgeData
+}
+
+func newCSR(arcs [][]arc) csr {
+	c := csr{
+		offsets: make([]int, len(arcs)+1),
+	}
+
+	for i, row := range arcs {
+		c.offsets[i+1] = c.offsets[i] + len(row)
+	}
+
+	c.targets = make([]int, 0, c.offsets[len(arcs)])
+	c.data = make([]edgeData, 0, c.offsets[len(arcs)])
+
+	for _, row := range arcs {
+		for _, edge := range row {
+			c.targets = append(c.targets, edge.to)
+			c.data = append(c.data, edge.edgeData)
+		}
+	}
+
+	return c
+}
+
+// transpose returns the reverse of c, in which each edge ends at its original
+// start vertex.
+func (c *csr) transpose() csr {
+	var (
+		n = len(c.offsets) - 1
+		t = csr{
+			offsets: make([]int, n+1),
+			targets: make([]int, len(c.targets)),
+			data:    make([]edgeData, len(c.data)),
+		}
+	)
+
+	for _, to := range c.targets {
+		t.offsets[to+1]++
+	}
+
+	for i := 0; i < n; i++ {
+		t.offsets[i+1] += t.offsets[i]
+	}
+
+	// Rows are filled in ascending order of their original start vertices,
+	// which keeps the targets of each transposed row sorted.
+	next := append([]int(nil), t.offsets[:n]...)
+	for from := 0; from < n; from++ {
+		for k := c.offsets[from]; k < c.offsets[from+1]; k++ {
+			to := c.targets[k]
+			t.targets[next[to]] = from
+			t.data[next[to]] = c.data[k]
+			next[to]++
+		}
+	}
+
+	return t
+}
+
+func (c *csr) degree(i int) int {
+	return c.offsets[i+1] - c.offsets[i]
+}
+
+// neighbors returns the keys of the distinct vertices adjacent to the ith
+// vertex, in key order.
+func (c *csr) neighbors(i int, keys []internal.Key) []internal.Key {
+	var (
+		lo, hi    = c.row(i)
+		neighbors = make([]internal.Key, 0, hi-lo)
+	)
+
+	for k := lo; k < hi; k++ {
+		if k == lo || c.targets[k] != c.targets[k-1] {
+			neighbors = append(neighbors, keys[c.targets[k]])
+		}
+	}
+
+	return neighbors
+}
+
+func (c *csr) row(i int) (int, int) {
+	return c.offsets[i], c.offsets[i+1]
+}
+
+// span returns the range of the ith vertex's edges that end at the jth vertex.
+func (c *csr) span(i int, j int) (int, int) {
+	lo, hi := c.row(i)
+
+	lo += sort.SearchInts(c.targets[lo:hi], j)
+	for end := lo; ; end++ {
+		if end == hi || c.targets[end] != j {
+			return lo, end
+		}
+	}
+}
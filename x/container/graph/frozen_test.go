@@ -0,0 +1,271 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrozen(t *testing.T) {
+	constructors := map[string]func(...graph.Option) *graph.Graph{
+		"directed":              graph.New,
+		"undirected":            graph.NewUndirected,
+		"multigraph":            graph.NewMultigraph,
+		"undirected multigraph": graph.NewUndirectedMultigraph,
+	}
+
+	for name, newGraph := range constructors {
+		for i := 0; i < 5; i++ {
+			t.Run(name+"/"+strconv.Itoa(i), func(t *testing.T) {
+				var (
+					rng  = rand.New(rand.NewSource(int64(i)))
+					g    = newGraph(graph.WithVertexIndex(indexValue))
+					keys = make([]graph.Key, 15)
+				)
+
+				for i := range keys {
+					keys[i] = g.AddVertex(i)
+				}
+
+				for i := 0; i < 40; i++ {
+					g.AddEdgeCost(
+						keys[rng.Intn(len(keys))],
+						keys[rng.Intn(len(keys))],
+						rng.Intn(10),
+					)
+				}
+
+				g.DeleteVertex(keys[rng.Intn(len(keys))])
+
+				requireFrozenEqual(t, g, g.Freeze(), keys)
+			})
+		}
+	}
+}
+
+func TestFrozenImmutable(t *testing.T) {
+	var (
+		g  = graph.New(graph.WithVertexIndex(indexValue))
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	g.AddEdgeCost(k1, k2, 3)
+
+	frozen := g.Freeze()
+
+	g.AddEdgeCost(k2, k1, 1)
+	g.SetVertexValue(k1, 10)
+	g.DeleteEdge(k1, k2)
+	g.AddVertex(3)
+
+	require.Equal(t, 2, frozen.Order())
+	require.Equal(t, 1, frozen.Size())
+	require.True(t, frozen.HasEdge(k1, k2))
+	require.False(t, frozen.HasEdge(k2, k1))
+	require.Equal(t, k1, lookup(t, frozen, "1"))
+
+	vertex, ok := frozen.Get(k1)
+	require.True(t, ok)
+	require.Equal(t, 1, vertex.Value())
+}
+
+func TestFrozenConcurrent(t *testing.T) {
+	var (
+		g    = graph.New()
+		keys = make([]graph.Key, 100)
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i)
+		if i > 0 {
+			g.AddEdgeCost(keys[i-1], keys[i], 1)
+			g.AddEdgeCost(keys[i/2], keys[i], i)
+		}
+	}
+
+	var (
+		frozen   = g.Freeze()
+		expected = g.FindPath(graph.Dijkstra, keys[0], keys[len(keys)-1])
+		wg       sync.WaitGroup
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 10; j++ {
+				actual := frozen.Dijkstra(keys[0], keys[len(keys)-1])
+				if actual.Cost != expected.Cost {
+					panic(fmt.Sprintf("expected %v, got %v", expected, actual))
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
+
+// requireFrozenEqual checks that frozen behaves identically to g, which must
+// not have changed since frozen was created from it.
+func requireFrozenEqual(
+	t *testing.T,
+	g *graph.Graph,
+	frozen *graph.Frozen,
+	keys []graph.Key,
+) {
+	require.Equal(t, g.IsDirected(), frozen.IsDirected())
+	require.Equal(t, g.IsMultigraph(), frozen.IsMultigraph())
+	require.Equal(t, g.Order(), frozen.Order())
+	require.Equal(t, g.Size(), frozen.Size())
+	require.ElementsMatch(
+		t,
+		edgeStrings(g, graph.Root),
+		frozenEdgeStrings(frozen, graph.Root),
+	)
+
+	keys = append(keys, graph.Key{})
+
+	for _, from := range keys {
+		requireFrozenVertexEqual(t, g, frozen, from)
+
+		for _, to := range keys {
+			require.Equal(t, g.HasEdge(from, to), frozen.HasEdge(from, to))
+
+			cost, ok := g.EdgeCost(from, to)
+			frozenCost, frozenOK := frozen.EdgeCost(from, to)
+			require.Equal(t, ok, frozenOK)
+			require.Equal(t, cost, frozenCost)
+
+			require.Equal(
+				t,
+				g.FindPath(graph.Dijkstra, from, to).Cost,
+				frozen.Dijkstra(from, to).Cost,
+			)
+			require.Equal(
+				t,
+				g.FindPath(graph.AStar(nil), from, to).Cost,
+				frozen.AStar(nil, from, to).Cost,
+			)
+			require.Equal(
+				t,
+				pathCosts(g.FindPaths(graph.KShortest(3), from, to)),
+				pathCosts(frozen.KShortest(3, from, to)),
+			)
+		}
+	}
+
+	for _, order := range []graph.TraversalOrder{
+		graph.LevelOrder,
+		graph.PreOrder,
+		graph.PostOrder,
+	} {
+		for _, direction := range []graph.Direction{
+			graph.Outgoing,
+			graph.Incoming,
+		} {
+			require.Equal(
+				t,
+				visits(g.Traverse, graph.Root, order, direction),
+				visits(frozen.Traverse, graph.Root, order, direction),
+			)
+		}
+	}
+}
+
+func requireFrozenVertexEqual(
+	t *testing.T,
+	g *graph.Graph,
+	frozen *graph.Frozen,
+	key graph.Key,
+) {
+	vertex, ok := g.Get(key)
+	frozenVertex, frozenOK := frozen.Get(key)
+	require.Equal(t, ok, frozenOK)
+	require.Equal(t, vertex.Value(), frozenVertex.Value())
+
+	require.Equal(t, g.OutDegree(key), frozen.OutDegree(key))
+	require.Equal(t, g.InDegree(key), frozen.InDegree(key))
+	require.Equal(t, g.Successors(key), frozen.Successors(key))
+	require.Equal(t, g.Predecessors(key), frozen.Predecessors(key))
+	require.ElementsMatch(
+		t,
+		edgeStrings(g, key),
+		frozenEdgeStrings(frozen, key),
+	)
+
+	if ok {
+		index := indexValue(vertex.Value())
+		require.Equal(t, lookup(t, g, index), lookup(t, frozen, index))
+	}
+}
+
+func frozenEdgeStrings(f *graph.Frozen, key graph.Key) (edges []string) {
+	f.VisitEdges(key, func(edge graph.Edge) bool {
+		edges = append(edges, fmt.Sprintf(
+			"%v->%v(%d)",
+			edge.Start.Value(),
+			edge.End.Value(),
+			edge.Cost,
+		))
+		return true
+	})
+	return
+}
+
+type traverseFunc = func(
+	graph.Key,
+	graph.TraversalOrder,
+	graph.Direction,
+	graph.TraversalVisitorFunc,
+)
+
+func visits(
+	traverse traverseFunc,
+	key graph.Key,
+	order graph.TraversalOrder,
+	direction graph.Direction,
+) (visits []graph.Visit) {
+	traverse(key, order, direction, func(visit graph.Visit) bool {
+		visits = append(visits, visit)
+		return true
+	})
+	return
+}
+
+func pathCosts(paths graph.Paths) (costs []int) {
+	for _, path := range paths {
+		costs = append(costs, path.Cost)
+	}
+	return
+}
+
+func indexValue(value interface{}) string {
+	return fmt.Sprint(value)
+}
@@ -22,14 +22,13 @@ package graph
 
 import (
 	"bytes"
+	"context"
 	"math"
 	"sync"
 
 	"github.com/mway/pkg/x/container/graph/internal"
 )
 
-// TODO(mway): proper A*
-
 var (
 	// Any TODOC
 	Any = Key{key: internal.Key(math.MaxUint64)}
@@ -50,40 +49,106 @@ type (
 	EdgeFilterFunc = func(Edge) bool
 	// EdgeVisitorFunc is used by Graph to yield visited edges to callers.
 	EdgeVisitorFunc = func(Edge) bool
+	// EdgeCostFunc is used by pathfinding algorithms to determine the cost of
+	// traversing an edge.
+	EdgeCostFunc = func(Edge) int
 	// FindPathFunc is used by Graph do perform pluggable pathing/costing for a
 	// single path.
 	FindPathFunc = func(graph *Graph, from Key, to Key) Path
 	// FindPathsFunc is used by Graph to perform pluggable pathing/costing for
 	// multiple paths.
 	FindPathsFunc = func(graph *Graph, from Key, to Key) Paths
+	// FindPathContextFunc is used by Graph to perform pluggable, cancellable
+	// pathing/costing for a single path.
+	FindPathContextFunc = func(
+		ctx context.Context,
+		graph *Graph,
+		from Key,
+		to Key,
+	) (Path, error)
+	// FindPathsContextFunc is used by Graph to perform pluggable, cancellable
+	// pathing/costing for multiple paths.
+	FindPathsContextFunc = func(
+		ctx context.Context,
+		graph *Graph,
+		from Key,
+		to Key,
+	) (Paths, error)
 )
 
 // A Graph is a basic data structure defined as a set of vertices and a set of
-// edges. Graphs may be either directed or undirected.
+// edges. Graphs may be either directed or undirected, and may optionally be
+// multigraphs, which allow parallel edges between the same pair of vertices.
+//
+// A Graph is safe for concurrent use. Methods that only read g hold a shared
+// lock, and thus may run concurrently with one another; methods that modify g
+// hold an exclusive lock. Visitors, filters, cost functions, and event
+// subscribers (see Subscribe) are called without the lock held, and thus may
+// call any method of g, including methods that modify it; see the
+// documentation of each method for which modifications are observed by the
+// remainder of the call. The exception is the function provided to
+// WithVertexIndex, which is called with the lock held.
+//
+// Operations composed of several reads, such as the pathfinding algorithms
+// (which read the edges of one vertex at a time), are not atomic, and may
+// observe modifications made concurrently. Use Freeze to obtain an immutable,
+// consistent view of g.
 type Graph struct {
-	lastKey  uint64
-	mtx      sync.Mutex
-	vertices map[internal.Key]Vertex
-	edges    map[internal.Key]map[internal.Key]int
-	redges   map[internal.Key]map[internal.Key]int
+	lastKey     uint64
+	lastEdgeKey uint64
+	size        int
+	mtx         sync.RWMutex
+	vertices    map[internal.Key]Vertex
+	edges       map[internal.Key]map[internal.Key]edgeList
+	redges      map[internal.Key]map[internal.Key]edgeList
+	edgeEnds    map[internal.Key][2]internal.Key
+	index       map[string]indexOwners
+
+	lastSubscriber uint64
+	subscribers    []subscriber
+	events         []Event
 
 	config struct {
 		undirected bool
+		multigraph bool
+		index      VertexIndexFunc
+		codec      ValueCodec
 	}
 }
 
 // New constructs a new directed graph.
-func New() *Graph {
-	return &Graph{
-		vertices: make(map[internal.Key]Vertex),
-		edges:    make(map[internal.Key]map[internal.Key]int),
-		redges:   make(map[internal.Key]map[internal.Key]int),
+func New(opts ...Option) *Graph {
+	g := &Graph{}
+	g.resetUnsafe()
+
+	for _, opt := range opts {
+		opt(g)
 	}
+
+	return g
 }
 
 // NewUndirected constructs a new undirected graph.
-func NewUndirected() *Graph {
-	g := New()
+func NewUndirected(opts ...Option) *Graph {
+	g := New(opts...)
+	g.config.undirected = true
+
+	return g
+}
+
+// NewMultigraph constructs a new directed multigraph. Each edge added to a
+// multigraph is distinct, even if it spans the same vertices as another edge,
+// and is identified by its own EdgeKey.
+func NewMultigraph(opts ...Option) *Graph {
+	g := New(opts...)
+	g.config.multigraph = true
+
+	return g
+}
+
+// NewUndirectedMultigraph constructs a new undirected multigraph.
+func NewUndirectedMultigraph(opts ...Option) *Graph {
+	g := NewMultigraph(opts...)
 	g.config.undirected = true
 
 	return g
@@ -98,31 +163,53 @@ func (g *Graph) AddEdge(from Key, to Key) bool {
 
 // AddEdgeCost behaves identically to AddEdge except using the provided cost.
 func (g *Graph) AddEdgeCost(from Key, to Key, cost int) bool {
+	return g.AddEdgeWith(from, to, EdgeAttrs{Cost: cost})
+}
+
+// AddEdgeWith behaves identically to AddEdge except using the provided
+// attributes. If g is not a multigraph and an edge spanning from and to already
+// exists, its attributes are replaced; otherwise, a new edge is added.
+func (g *Graph) AddEdgeWith(from Key, to Key, attrs EdgeAttrs) bool {
+	_, ok := g.AddEdgeKey(from, to, attrs)
+	return ok
+}
+
+// AddEdgeKey behaves identically to AddEdgeWith, but also returns the key of
+// the added edge. The key is only non-zero if g is a multigraph.
+func (g *Graph) AddEdgeKey(from Key, to Key, attrs EdgeAttrs) (EdgeKey, bool) {
 	g.mtx.Lock()
-	defer g.mtx.Unlock()
+	defer g.unlockAndPublish()
 
 	if _, exists := g.vertices[from.key]; !exists {
-		return false
+		return EdgeKey{}, false
 	}
 
 	if _, exists := g.vertices[to.key]; !exists {
-		return false
+		return EdgeKey{}, false
+	}
+
+	data := newEdgeData(attrs)
+	if g.config.multigraph {
+		g.lastEdgeKey++
+		data.key = internal.Key(g.lastEdgeKey)
 	}
 
-	edges := g.getEdgesUnsafe(from.key)
-	edges[to.key] = cost
+	typ := EdgeAdded
+	if _, exists := g.edges[from.key][to.key]; exists && !g.config.multigraph {
+		typ = EdgeUpdated
+	}
 
-	edges = g.getReverseEdgesUnsafe(to.key)
-	edges[from.key] = cost
+	g.putEdgeUnsafe(from.key, to.key, data)
+	g.recordEdgeUnsafe(typ, from.key, to.key, data)
 
-	return true
+	return newEdgeKey(data.key), true
 }
 
 // AddVertex adds a new vertex containing value to the graph and returns its
 // corresponding Key for subsequent lookup.
 func (g *Graph) AddVertex(value interface{}) Key {
 	g.mtx.Lock()
-	defer g.mtx.Unlock()
+	defer g.unlockAndPublish()
 
 	g.lastKey++
 
@@ -136,6 +223,8 @@ func (g *Graph) AddVertex(value interface{}) Key {
 	)
 
 	g.vertices[key] = node
+	g.indexVertexUnsafe(node)
+	g.recordUnsafe(Event{Type: VertexAdded, Vertex: node})
 
 	return newKey(key)
 }
@@ -146,49 +235,133 @@ func (g *Graph) AddVertex(value interface{}) Key {
 // fragmenting the underlying graph or isolating vertices.
 func (g *Graph) DeleteVertex(key Key) {
 	g.mtx.Lock()
-	defer g.mtx.Unlock()
+	defer g.unlockAndPublish()
 
 	g.deleteEdgeUnsafe(key, Any)
 	g.deleteEdgeUnsafe(Any, key)
 
-	_, ok := g.vertices[key.key]
+	vertex, ok := g.vertices[key.key]
 	if !ok {
 		return
 	}
 
+	g.unindexVertexUnsafe(vertex)
 	delete(g.vertices, key.key)
+	g.recordUnsafe(Event{Type: VertexRemoved, Vertex: vertex})
 }
 
 // DeleteEdge deletes the edge spanning vertices from and to, if such an edge
-// exists. If the graph is undirected, the reverse edge will also be deleted.
+// exists. If the graph is undirected, the reverse edge will also be deleted. If
+// the graph is a multigraph, all parallel edges spanning from and to are
+// deleted; use DeleteEdgeKey to delete a single edge.
 func (g *Graph) DeleteEdge(from Key, to Key) {
 	g.mtx.Lock()
-	defer g.mtx.Unlock()
+	defer g.unlockAndPublish()
 
 	g.deleteEdgeUnsafe(from, to)
 }
 
+// DeleteEdgeKey deletes the multigraph edge represented by key, if it exists.
+// If the graph is undirected, the edge is deleted in both directions.
+func (g *Graph) DeleteEdgeKey(key EdgeKey) {
+	g.mtx.Lock()
+	defer g.unlockAndPublish()
+
+	ends, ok := g.edgeEnds[key.key]
+	if !ok {
+		return
+	}
+
+	for _, data := range g.edges[ends[0]][ends[1]] {
+		if data.key == key.key {
+			g.recordEdgeUnsafe(EdgeRemoved, ends[0], ends[1], data)
+		}
+	}
+
+	g.deleteKeyUnsafe(ends[0], ends[1], key.key)
+	if g.config.undirected {
+		g.deleteKeyUnsafe(ends[1], ends[0], key.key)
+	}
+
+	delete(g.edgeEnds, key.key)
+	g.size--
+}
+
 // Get gets the Vertex represented by key, if it exists.
 func (g *Graph) Get(key Key) (Vertex, bool) {
-	g.mtx.Lock()
-	defer g.mtx.Unlock()
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
 
 	node, ok := g.vertices[key.key]
 	return node, ok
 }
 
+// Lookup returns the key of the vertex whose value has the given index key, as
+// determined by the function provided to WithVertexIndex. If g was not
+// constructed with WithVertexIndex, Lookup always returns false.
+func (g *Graph) Lookup(index string) (Key, bool) {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	owners, ok := g.index[index]
+	if !ok {
+		return _zeroKey, false
+	}
+
+	return newKey(owners.owner()), true
+}
+
+// SetVertexValue replaces the value of the vertex represented by key, returning
+// false if no such vertex exists. Vertex values previously obtained from g,
+// including those of edges, retain the old value.
+func (g *Graph) SetVertexValue(key Key, value interface{}) bool {
+	g.mtx.Lock()
+	defer g.unlockAndPublish()
+
+	vertex, ok := g.vertices[key.key]
+	if !ok {
+		return false
+	}
+
+	g.unindexVertexUnsafe(vertex)
+	vertex.value = value
+	g.vertices[key.key] = vertex
+	g.indexVertexUnsafe(vertex)
+	g.recordUnsafe(Event{Type: VertexUpdated, Vertex: vertex})
+
+	return true
+}
+
+// IsDirected returns whether g is a directed graph.
+func (g *Graph) IsDirected() bool {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return !g.config.undirected
+}
+
+// IsMultigraph returns whether g is a multigraph.
+func (g *Graph) IsMultigraph() bool {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return g.config.multigraph
+}
+
 // FilterVertices returns a copy of g, filtering the vertices of g based on the
 // provided predicate filter: for a given vertex v, if filter(v) returns true, v
 // remains part of g; if filter(v) returns false, however, v - as well as any
 // edges referencing v - are removed from g.
 //
 // It is possible for filters to create disjoint (multi-part) sub-graphs, or to
-// introduce vertex isolation.
+// introduce vertex isolation. Since filter is applied to a copy of g, it may
+// call methods of g, but modifications made to g are not reflected in the
+// returned graph.
 func (g *Graph) FilterVertices(filter VertexFilterFunc) *Graph {
-	g.mtx.Lock()
-	defer g.mtx.Unlock()
-
+	g.mtx.RLock()
 	dup := g.cloneUnsafe()
+	g.mtx.RUnlock()
+
 	for key, vertex := range dup.vertices {
 		if filter(vertex) {
 			continue
@@ -203,28 +376,29 @@ func (g *Graph) FilterVertices(filter VertexFilterFunc) *Graph {
 // FilterEdges returns a copy of g, filtering the edges of g based on the
 // provided predicate filter: for a given edge e, if filter(e) returns true, e
 // remains part of g; if filter(e) returns false, however, e is removed from g.
+// If g is undirected, filter is called once per pair of incident vertices, and
+// removing an edge removes it in both directions.
 //
 // A critical difference between FilterVertices and FilterEdges is that the
 // former will prune edges (there is no such thing as non-incident/adjacent
 // edges) whereas the latter will remove only edges (potentially introducing
-// disjoint/multi-part sub-graphs or vertex isolation).
+// disjoint/multi-part sub-graphs or vertex isolation). As with FilterVertices,
+// filter may call methods of g.
 func (g *Graph) FilterEdges(filter EdgeFilterFunc) *Graph {
-	g.mtx.Lock()
-	defer g.mtx.Unlock()
-
-	dup := g.cloneUnsafe()
-	for start, edges := range g.edges {
-		for end, cost := range edges {
-			keep := filter(Edge{
-				Start: g.vertices[start],
-				End:   g.vertices[end],
-				Cost:  cost,
-			})
-			if keep {
-				continue
-			}
-
-			dup.DeleteEdge(newKey(start), newKey(end))
+	g.mtx.RLock()
+	var (
+		dup   = g.cloneUnsafe()
+		edges = g.allEdgesUnsafe()
+	)
+	g.mtx.RUnlock()
+
+	for _, edge := range edges {
+		switch {
+		case filter(edge):
+		case dup.config.multigraph:
+			dup.DeleteEdgeKey(edge.Key())
+		default:
+			dup.DeleteEdge(edge.Start.Key(), edge.End.Key())
 		}
 	}
 
@@ -242,18 +416,53 @@ func (g *Graph) FindPaths(algo FindPathsFunc, from Key, to Key) Paths {
 	return algo(g, from, to)
 }
 
+// FindPathContext uses algo to find a path spanning vertices from and to,
+// stopping once ctx is done. If algo is stopped, it returns an error, which is
+// typically ctx.Err(), along with any partial result; see the documentation of
+// algo for what such a result contains. To stop algo with a Canceler (from the
+// x/sync/canceler package), use the context returned by its Context method.
+func (g *Graph) FindPathContext(
+	ctx context.Context,
+	algo FindPathContextFunc,
+	from Key,
+	to Key,
+) (Path, error) {
+	return algo(ctx, g, from, to)
+}
+
+// FindPathsContext uses algo to find paths spanning vertices from and to,
+// stopping once ctx is done. See FindPathContext.
+func (g *Graph) FindPathsContext(
+	ctx context.Context,
+	algo FindPathsContextFunc,
+	from Key,
+	to Key,
+) (Paths, error) {
+	return algo(ctx, g, from, to)
+}
+
 // Order returns the order of the graph (the number of vertices).
 func (g *Graph) Order() int {
-	g.mtx.Lock()
-	defer g.mtx.Unlock()
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
 
 	return len(g.vertices)
 }
 
+// Size returns the size of the graph (the number of edges). Each edge of an
+// undirected graph is counted once, and each of a multigraph's parallel edges
+// is counted separately.
+func (g *Graph) Size() int {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return g.size
+}
+
 // String provides a string representation of the graph.
 func (g *Graph) String() string {
-	g.mtx.Lock()
-	defer g.mtx.Unlock()
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
 
 	var buf bytes.Buffer
 
@@ -277,144 +486,311 @@ func (g *Graph) String() string {
 	return buf.String()
 }
 
-// VisitEdges uses fn to visit each edge, starting at the vertex key.
+// VisitEdges uses fn to visit each edge, starting at the vertex key. If key is
+// Root, every edge in the graph is visited; for undirected graphs, each edge is
+// then visited only once, starting at whichever incident vertex has the lower
+// key. Each of a multigraph's parallel edges is visited separately. Visiting
+// stops when fn returns false.
+//
+// The edges to visit are gathered before fn is first called, so fn may modify
+// g; such modifications do not change which edges are visited.
 func (g *Graph) VisitEdges(key Key, fn EdgeVisitorFunc) {
-	g.mtx.Lock()
-	defer g.mtx.Unlock()
-
-	visit := func(start internal.Key) {
-		for end, cost := range g.edges[start] {
-			res := fn(Edge{
-				Start: g.vertices[start],
-				End:   g.vertices[end],
-				Cost:  cost,
-			})
-			if !res {
-				break
-			}
-		}
-	}
-
+	g.mtx.RLock()
+	var edges []Edge
 	if key != Root {
-		visit(key.key)
-		return
+		edges = g.appendEdgesUnsafe(nil, key.key, false)
+	} else {
+		edges = g.allEdgesUnsafe()
 	}
+	g.mtx.RUnlock()
 
-	for key := range g.edges {
-		visit(key)
-	}
+	visitEdgeSlice(edges, fn)
 }
 
-// VisitVertices uses fn to visit each vertex, starting at the vertex key.
+// VisitVertices uses fn to visit each vertex, starting at the vertex key, in
+// breadth-first order. It is equivalent to Traverse with LevelOrder and
+// Outgoing.
 func (g *Graph) VisitVertices(key Key, fn VertexVisitorFunc) {
-	var (
-		visited = make(map[internal.Key]struct{})
-		queue   []Vertex
-	)
-
-	g.mtx.Lock()
-	defer g.mtx.Unlock()
+	g.Traverse(key, LevelOrder, Outgoing, func(visit Visit) bool {
+		return fn(visit.Vertex)
+	})
+}
 
-	if key != Root {
-		queue = append(queue, g.vertices[key.key])
-	} else {
-		for _, node := range g.vertices {
-			queue = append(queue, node)
-		}
+func (g *Graph) addEdgeUnsafe(
+	from internal.Key,
+	to internal.Key,
+	data edgeData,
+) {
+	list := edgeList{data}
+	if g.config.multigraph {
+		list = g.edges[from][to].with(data)
 	}
 
-	for len(queue) > 0 {
-		node := queue[0]
-		queue = queue[1:]
-
-		if _, skip := visited[node.key]; skip {
-			continue
-		}
-		visited[node.key] = struct{}{}
+	g.getEdgesUnsafe(from)[to] = list
+	g.getReverseEdgesUnsafe(to)[from] = list
+}
 
-		if !fn(node) {
-			break
-		}
+// putEdgeUnsafe adds an edge spanning from and to, as well as its reverse if g
+// is undirected.
+func (g *Graph) putEdgeUnsafe(
+	from internal.Key,
+	to internal.Key,
+	data edgeData,
+) {
+	if _, exists := g.edges[from][to]; g.config.multigraph || !exists {
+		g.size++
+	}
 
-		for edge := range g.edges[node.key] {
-			if _, skip := visited[edge]; skip {
-				continue
-			}
+	g.addEdgeUnsafe(from, to, data)
+	if g.config.undirected && from != to {
+		g.addEdgeUnsafe(to, from, data)
+	}
 
-			queue = append(queue, g.vertices[edge])
-		}
+	if g.config.multigraph {
+		g.edgeEnds[data.key] = [2]internal.Key{from, to}
 	}
 }
 
 func (g *Graph) cloneUnsafe() *Graph {
-	clone := &Graph{
-		lastKey:  g.lastKey,
-		vertices: make(map[internal.Key]Vertex, len(g.vertices)),
-		edges:    make(map[internal.Key]map[internal.Key]int, len(g.edges)),
-		redges:   make(map[internal.Key]map[internal.Key]int, len(g.redges)),
-	}
-
-	for key, vertex := range g.vertices {
-		clone.vertices[key] = vertex
-	}
+	clone := g.cloneVerticesUnsafe()
 
 	for src, edges := range g.edges {
-		newedges := make(map[internal.Key]int, len(edges))
-		for key, cost := range edges {
-			newedges[key] = cost
+		newedges := make(map[internal.Key]edgeList, len(edges))
+		for key, list := range edges {
+			newedges[key] = list
 		}
 		clone.edges[src] = newedges
 	}
 
 	for src, edges := range g.redges {
-		newedges := make(map[internal.Key]int, len(edges))
-		for key, cost := range edges {
-			newedges[key] = cost
+		newedges := make(map[internal.Key]edgeList, len(edges))
+		for key, list := range edges {
+			newedges[key] = list
 		}
 		clone.redges[src] = newedges
 	}
 
-	clone.config.undirected = g.config.undirected
+	for key, ends := range g.edgeEnds {
+		clone.edgeEnds[key] = ends
+	}
+
+	clone.size = g.size
+
+	return clone
+}
+
+// cloneVerticesUnsafe returns a copy of g that retains g's vertices and keys,
+// but none of its edges.
+func (g *Graph) cloneVerticesUnsafe() *Graph {
+	clone := &Graph{
+		lastKey:     g.lastKey,
+		lastEdgeKey: g.lastEdgeKey,
+		vertices:    make(map[internal.Key]Vertex, len(g.vertices)),
+		edges:       make(map[internal.Key]map[internal.Key]edgeList, len(g.edges)),
+		redges:      make(map[internal.Key]map[internal.Key]edgeList, len(g.redges)),
+		edgeEnds:    make(map[internal.Key][2]internal.Key, len(g.edgeEnds)),
+		index:       make(map[string]indexOwners, len(g.index)),
+	}
+
+	for key, vertex := range g.vertices {
+		clone.vertices[key] = vertex
+	}
+
+	for index, owners := range g.index {
+		clone.index[index] = owners
+	}
+
+	clone.config = g.config
 
 	return clone
 }
 
 func (g *Graph) deleteEdgeUnsafe(from Key, to Key) {
-	if from == to {
+	switch {
+	case from == Any && to == Any:
 		return
+	case from == Any:
+		for start := range g.redges[to.key] {
+			g.deletePairUnsafe(start, to.key)
+		}
+	case to == Any:
+		for end := range g.edges[from.key] {
+			g.deletePairUnsafe(from.key, end)
+		}
+	default:
+		g.deletePairUnsafe(from.key, to.key)
+	}
+}
+
+func (g *Graph) deletePairUnsafe(from internal.Key, to internal.Key) {
+	for _, data := range g.edges[from][to] {
+		delete(g.edgeEnds, data.key)
+		g.recordEdgeUnsafe(EdgeRemoved, from, to, data)
+	}
+
+	g.size -= len(g.edges[from][to])
+
+	delete(g.edges[from], to)
+	delete(g.redges[to], from)
+
+	if g.config.undirected {
+		delete(g.edges[to], from)
+		delete(g.redges[from], to)
 	}
+}
 
-	if from != Any && to != Any {
-		delete(g.edges[from.key], to.key)
-		delete(g.redges[to.key], from.key)
+// deleteKeyUnsafe deletes the edge with the given key from the edges spanning
+// from and to.
+func (g *Graph) deleteKeyUnsafe(
+	from internal.Key,
+	to internal.Key,
+	key internal.Key,
+) {
+	list := g.edges[from][to].without(key)
+	if len(list) == 0 {
+		delete(g.edges[from], to)
+		delete(g.redges[to], from)
 		return
 	}
 
-	if from == Any {
-		if _, ok := g.vertices[to.key]; !ok {
-			return
-		}
+	g.edges[from][to] = list
+	g.redges[to][from] = list
+}
+
+// vertex returns the vertex represented by key, if it exists. It implements
+// edgeSource.
+func (g *Graph) vertex(key internal.Key) (Vertex, bool) {
+	return g.Get(newKey(key))
+}
+
+// visitEdges uses fn to visit the edges starting at the vertex key. It
+// implements edgeSource.
+func (g *Graph) visitEdges(key internal.Key, fn EdgeVisitorFunc) {
+	g.mtx.RLock()
+	edges := g.appendEdgesUnsafe(nil, key, false)
+	g.mtx.RUnlock()
+
+	visitEdgeSlice(edges, fn)
+}
+
+// cheapestEdge returns the edge spanning from and to that is cheapest according
+// to cost.
+func (g *Graph) cheapestEdge(
+	from internal.Key,
+	to internal.Key,
+	cost EdgeCostFunc,
+) (Edge, bool) {
+	g.mtx.RLock()
+	edges := make([]Edge, 0, len(g.edges[from][to]))
+	for _, data := range g.edges[from][to] {
+		edges = append(edges, g.newEdgeUnsafe(from, to, data))
+	}
+	g.mtx.RUnlock()
+
+	var (
+		min   Edge
+		found bool
+	)
 
-		for from := range g.redges[to.key] {
-			delete(g.edges[from], to.key)
-			delete(g.redges[to.key], from)
+	for _, edge := range edges {
+		if !found || cost(edge) < cost(min) {
+			min, found = edge, true
 		}
+	}
+
+	return min, found
+}
+
+// resetUnsafe removes all vertices and edges from g, retaining its
+// configuration.
+func (g *Graph) resetUnsafe() {
+	g.lastKey = 0
+	g.lastEdgeKey = 0
+	g.size = 0
+	g.vertices = make(map[internal.Key]Vertex)
+	g.edges = make(map[internal.Key]map[internal.Key]edgeList)
+	g.redges = make(map[internal.Key]map[internal.Key]edgeList)
+	g.edgeEnds = make(map[internal.Key][2]internal.Key)
+	g.index = make(map[string]indexOwners)
+}
+
+// indexVertexUnsafe adds vertex to g's vertex index, if g has one. The vertex
+// becomes the owner of its index key.
+func (g *Graph) indexVertexUnsafe(vertex Vertex) {
+	if g.config.index != nil {
+		index := g.config.index(vertex.value)
+		g.index[index] = g.index[index].with(vertex.key)
+	}
+}
+
+// unindexVertexUnsafe removes vertex from g's vertex index, if g has one. If
+// other vertices share the vertex's index key, the most recently indexed of
+// them becomes its owner.
+func (g *Graph) unindexVertexUnsafe(vertex Vertex) {
+	if g.config.index == nil {
+		return
+	}
+
+	index := g.config.index(vertex.value)
+	if owners := g.index[index].without(vertex.key); len(owners) > 0 {
+		g.index[index] = owners
 	} else {
-		if _, ok := g.vertices[from.key]; !ok {
-			return
+		delete(g.index, index)
+	}
+}
+
+// indexOwners holds the vertices sharing an index key, in the order they were
+// indexed; the last of them owns the key. An indexOwners is never modified in
+// place, and thus may be shared between graphs.
+type indexOwners []internal.Key
+
+// with returns a copy of o that ends with key.
+func (o indexOwners) with(key internal.Key) indexOwners {
+	return append(o.without(key), key)
+}
+
+// without returns a copy of o that excludes key.
+func (o indexOwners) without(key internal.Key) indexOwners {
+	dup := make(indexOwners, 0, len(o)+1)
+	for _, owner := range o {
+		if owner != key {
+			dup = append(dup, owner)
 		}
+	}
+
+	return dup
+}
 
-		for to := range g.edges[from.key] {
-			delete(g.edges[from.key], to)
-			delete(g.redges[to], from.key)
+// within returns a copy of o that excludes any keys not in include.
+func (o indexOwners) within(include map[internal.Key]struct{}) indexOwners {
+	dup := make(indexOwners, 0, len(o))
+	for _, owner := range o {
+		if _, ok := include[owner]; ok {
+			dup = append(dup, owner)
 		}
 	}
+
+	return dup
+}
+
+// owner returns the key of the vertex that owns the index key. o must not be
+// empty.
+func (o indexOwners) owner() internal.Key {
+	return o[len(o)-1]
 }
 
-func (g *Graph) getEdgesUnsafe(key internal.Key) map[internal.Key]int {
+func (g *Graph) newEdgeUnsafe(
+	start internal.Key,
+	end internal.Key,
+	data edgeData,
+) Edge {
+	return newEdge(g.vertices[start], g.vertices[end], data)
+}
+
+func (g *Graph) getEdgesUnsafe(key internal.Key) map[internal.Key]edgeList {
 	edges, ok := g.edges[key]
 	if !ok {
-		edges = make(map[internal.Key]int)
+		edges = make(map[internal.Key]edgeList)
 		g.edges[key] = edges
 	}
 
@@ -423,12 +799,53 @@ func (g *Graph) getEdgesUnsafe(key internal.Key) map[internal.Key]int {
 
 func (g *Graph) getReverseEdgesUnsafe(
 	key internal.Key,
-) map[internal.Key]int {
+) map[internal.Key]edgeList {
 	edges, ok := g.redges[key]
 	if !ok {
-		edges = make(map[internal.Key]int)
+		edges = make(map[internal.Key]edgeList)
 		g.redges[key] = edges
 	}
 
 	return edges
 }
+
+// allEdgesUnsafe returns every edge in g, including each edge of an undirected
+// graph once.
+func (g *Graph) allEdgesUnsafe() []Edge {
+	edges := make([]Edge, 0, g.size)
+	for start := range g.edges {
+		edges = g.appendEdgesUnsafe(edges, start, g.config.undirected)
+	}
+
+	return edges
+}
+
+// appendEdgesUnsafe appends the edges starting at start to edges, returning the
+// result. If dedupe is true, edges ending at a vertex with a lower key than
+// start are skipped.
+func (g *Graph) appendEdgesUnsafe(
+	edges []Edge,
+	start internal.Key,
+	dedupe bool,
+) []Edge {
+	for end, list := range g.edges[start] {
+		if dedupe && end < start {
+			continue
+		}
+
+		for _, data := range list {
+			edges = append(edges, g.newEdgeUnsafe(start, end, data))
+		}
+	}
+
+	return edges
+}
+
+// visitEdgeSlice visits edges with fn until fn returns false.
+func visitEdgeSlice(edges []Edge, fn EdgeVisitorFunc) {
+	for _, edge := range edges {
+		if !fn(edge) {
+			return
+		}
+	}
+}
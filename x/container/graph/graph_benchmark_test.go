@@ -0,0 +1,158 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+)
+
+const _benchGridSize = 32
+
+// newBenchGraph returns a directed grid graph of size*size vertices, in which
+// each vertex has an edge to its right and lower neighbors, along with the
+// keys of its vertices in row-major order.
+func newBenchGraph(size int) (*graph.Graph, []graph.Key) {
+	var (
+		g    = graph.New()
+		keys = make([]graph.Key, size*size)
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i)
+	}
+
+	for i, key := range keys {
+		if (i+1)%size != 0 {
+			g.AddEdgeCost(key, keys[i+1], 1+i%3)
+		}
+
+		if i+size < len(keys) {
+			g.AddEdgeCost(key, keys[i+size], 1+i%5)
+		}
+	}
+
+	return g, keys
+}
+
+func BenchmarkGraphGet(b *testing.B) {
+	g, keys := newBenchGraph(_benchGridSize)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			g.Get(keys[i%len(keys)])
+		}
+	})
+}
+
+func BenchmarkGraphDijkstra(b *testing.B) {
+	g, keys := newBenchGraph(_benchGridSize)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			g.FindPath(graph.Dijkstra, keys[0], keys[len(keys)-1])
+		}
+	})
+}
+
+func BenchmarkFrozenDijkstra(b *testing.B) {
+	g, keys := newBenchGraph(_benchGridSize)
+	frozen := g.Freeze()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			frozen.Dijkstra(keys[0], keys[len(keys)-1])
+		}
+	})
+}
+
+func BenchmarkGraphAddDeleteEdge(b *testing.B) {
+	var (
+		g, keys = newBenchGraph(_benchGridSize)
+		sink    = g.AddVertex(-1) // writes to it never touch grid edges
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			from := keys[i%len(keys)]
+			g.AddEdgeCost(from, sink, 1)
+			g.DeleteEdge(from, sink)
+		}
+	})
+}
+
+// BenchmarkGraphMixed measures throughput of concurrent reads (vertex lookups
+// and edge visits) interleaved with writes at varying ratios. Each operation
+// counts as a single iteration.
+func BenchmarkGraphMixed(b *testing.B) {
+	for _, writePercent := range []int{0, 1, 10, 50} {
+		b.Run(fmt.Sprintf("writes=%d%%", writePercent), func(b *testing.B) {
+			benchmarkMixed(b, writePercent)
+		})
+	}
+}
+
+func benchmarkMixed(b *testing.B, writePercent int) {
+	var (
+		g, keys = newBenchGraph(_benchGridSize)
+		sink    = g.AddVertex(-1) // writes to it never touch grid edges
+		ops     uint64
+	)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			var (
+				op  = int(atomic.AddUint64(&ops, 1))
+				key = keys[op%len(keys)]
+			)
+
+			switch {
+			case op%100 < writePercent:
+				g.AddEdgeCost(key, sink, op%9)
+				g.DeleteEdge(key, sink)
+			case op%2 == 0:
+				g.Get(key)
+			default:
+				g.VisitEdges(key, func(graph.Edge) bool {
+					return true
+				})
+			}
+		}
+	})
+}
@@ -24,6 +24,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/mway/pkg/x/container/graph"
@@ -355,3 +356,534 @@ func TestGraphString(t *testing.T) {
 	require.Contains(t, parts, "2")
 	require.Contains(t, parts, "1\t2")
 }
+
+func TestGraphIsDirected(t *testing.T) {
+	require.True(t, graph.New().IsDirected())
+	require.False(t, graph.NewUndirected().IsDirected())
+	require.False(t, graph.NewUndirected().FilterEdges(func(graph.Edge) bool {
+		return true
+	}).IsDirected())
+}
+
+func TestGraphUndirectedEdges(t *testing.T) {
+	var (
+		g  = graph.NewUndirected()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+	)
+
+	require.True(t, g.AddEdgeCost(k1, k2, 2))
+	require.True(t, g.AddEdgeCost(k3, k2, 3))
+
+	// Each vertex sees every incident edge as outgoing.
+	require.ElementsMatch(t, []string{"1->2(2)"}, edgeStrings(g, k1))
+	require.ElementsMatch(t, []string{"2->1(2)", "2->3(3)"}, edgeStrings(g, k2))
+	require.ElementsMatch(t, []string{"3->2(3)"}, edgeStrings(g, k3))
+
+	// Visiting from the root yields each edge once, lowest key first.
+	require.ElementsMatch(
+		t,
+		[]string{"1->2(2)", "2->3(3)"},
+		edgeStrings(g, graph.Root),
+	)
+
+	// Deleting either direction deletes the edge.
+	g.DeleteEdge(k2, k1)
+	require.Empty(t, edgeStrings(g, k1))
+	require.ElementsMatch(t, []string{"2->3(3)"}, edgeStrings(g, k2))
+
+	g.DeleteVertex(k3)
+	require.Empty(t, edgeStrings(g, graph.Root))
+}
+
+func TestGraphUndirectedVisitVertices(t *testing.T) {
+	var (
+		g  = graph.NewUndirected()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+	)
+
+	g.AddEdge(k2, k1)
+	g.AddEdge(k3, k2)
+	g.AddVertex(5)
+
+	var actual []interface{}
+	g.VisitVertices(k1, func(vertex graph.Vertex) bool {
+		actual = append(actual, vertex.Value())
+		return true
+	})
+
+	require.ElementsMatch(t, []interface{}{1, 2, 3}, actual)
+
+	g.AddEdge(k4, k3)
+
+	actual = nil
+	g.VisitVertices(k4, func(vertex graph.Vertex) bool {
+		actual = append(actual, vertex.Value())
+		return true
+	})
+
+	require.ElementsMatch(t, []interface{}{1, 2, 3, 4}, actual)
+}
+
+func TestGraphUndirectedFilter(t *testing.T) {
+	var (
+		g     = graph.NewUndirected()
+		k1    = g.AddVertex(1)
+		k2    = g.AddVertex(2)
+		k3    = g.AddVertex(3)
+		calls int
+	)
+
+	g.AddEdge(k1, k2)
+	g.AddEdge(k2, k3)
+
+	filtered := g.FilterEdges(func(edge graph.Edge) bool {
+		calls++
+		return edge.Start.Key() != k1
+	})
+
+	require.Equal(t, 2, calls)
+	require.Empty(t, edgeStrings(filtered, k1))
+	require.ElementsMatch(t, []string{"2->3(1)"}, edgeStrings(filtered, k2))
+	require.ElementsMatch(t, []string{"3->2(1)"}, edgeStrings(filtered, k3))
+
+	filtered = g.FilterVertices(func(vertex graph.Vertex) bool {
+		return vertex.Key() != k3
+	})
+
+	require.ElementsMatch(t, []string{"2->1(1)"}, edgeStrings(filtered, k2))
+	require.ElementsMatch(
+		t,
+		[]string{"1->2(1)"},
+		edgeStrings(filtered, graph.Root),
+	)
+}
+
+func TestGraphUndirectedString(t *testing.T) {
+	var (
+		g  = graph.NewUndirected()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	g.AddEdge(k1, k2)
+
+	parts := strings.Split(strings.TrimSpace(g.String()), "\n")
+	require.Equal(t, 2, len(parts))
+	require.Contains(t, parts, "1\t2")
+	require.Contains(t, parts, "2\t1")
+}
+
+func TestGraphUndirectedDijkstra(t *testing.T) {
+	var (
+		g  = graph.NewUndirected()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+	)
+
+	// Each edge is added "against" the direction of travel.
+	g.AddEdgeCost(k2, k1, 1)
+	g.AddEdgeCost(k3, k2, 1)
+	g.AddEdgeCost(k4, k3, 1)
+	g.AddEdgeCost(k4, k1, 5)
+
+	require.Equal(t, graph.Path{
+		Cost:     3,
+		Vertices: []graph.Key{k1, k2, k3, k4},
+	}, g.FindPath(graph.Dijkstra, k1, k4))
+	require.Equal(t, graph.Path{
+		Cost:     3,
+		Vertices: []graph.Key{k4, k3, k2, k1},
+	}, g.FindPath(graph.Dijkstra, k4, k1))
+}
+
+func TestGraphSelfLoop(t *testing.T) {
+	for _, g := range []*graph.Graph{graph.New(), graph.NewUndirected()} {
+		k1 := g.AddVertex(1)
+
+		g.AddEdge(k1, k1)
+		require.ElementsMatch(t, []string{"1->1(1)"}, edgeStrings(g, graph.Root))
+
+		g.DeleteEdge(k1, k1)
+		require.Empty(t, edgeStrings(g, graph.Root))
+	}
+}
+
+func TestGraphVisitEdgesStop(t *testing.T) {
+	var (
+		g    = graph.New()
+		keys = []graph.Key{g.AddVertex(1), g.AddVertex(2), g.AddVertex(3)}
+	)
+
+	for _, from := range keys {
+		for _, to := range keys {
+			g.AddEdge(from, to)
+		}
+	}
+
+	var n int
+	g.VisitEdges(graph.Root, func(graph.Edge) bool {
+		n++
+		return false
+	})
+
+	require.Equal(t, 1, n)
+}
+
+func TestGraphMultigraph(t *testing.T) {
+	var (
+		g  = graph.NewMultigraph()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+	)
+
+	require.True(t, g.IsMultigraph())
+	require.False(t, graph.New().IsMultigraph())
+
+	e1, ok := g.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 5})
+	require.True(t, ok)
+	e2, ok := g.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 2})
+	require.True(t, ok)
+	require.NotEqual(t, e1, e2)
+	require.NotEqual(t, graph.EdgeKey{}, e1)
+
+	_, ok = g.AddEdgeKey(k1, graph.Key{}, graph.EdgeAttrs{})
+	require.False(t, ok)
+
+	g.AddEdgeCost(k2, k3, 1)
+	g.AddEdgeCost(k2, k3, 1)
+
+	require.ElementsMatch(t, []string{
+		"1->2(5)",
+		"1->2(2)",
+		"2->3(1)",
+		"2->3(1)",
+	}, edgeStrings(g, graph.Root))
+	require.Equal(t, graph.Path{
+		Cost:     3,
+		Vertices: []graph.Key{k1, k2, k3},
+	}, g.FindPath(graph.Dijkstra, k1, k3))
+
+	g.VisitEdges(k1, func(edge graph.Edge) bool {
+		switch edge.Cost {
+		case 5:
+			require.Equal(t, e1, edge.Key())
+		case 2:
+			require.Equal(t, e2, edge.Key())
+		}
+		return true
+	})
+
+	// Deleting by key removes only that edge, in copies as well.
+	dup := g.FilterEdges(func(edge graph.Edge) bool {
+		return edge.Cost != 5
+	})
+	g.DeleteEdgeKey(e2)
+
+	require.ElementsMatch(t, []string{
+		"1->2(5)",
+		"2->3(1)",
+		"2->3(1)",
+	}, edgeStrings(g, graph.Root))
+	require.ElementsMatch(t, []string{
+		"1->2(2)",
+		"2->3(1)",
+		"2->3(1)",
+	}, edgeStrings(dup, graph.Root))
+	require.Equal(t, graph.Path{
+		Cost:     6,
+		Vertices: []graph.Key{k1, k2, k3},
+	}, g.FindPath(graph.Dijkstra, k1, k3))
+
+	// Deleting by vertices removes all parallel edges.
+	g.DeleteEdge(k2, k3)
+	require.ElementsMatch(t, []string{"1->2(5)"}, edgeStrings(g, graph.Root))
+
+	g.DeleteVertex(k1)
+	require.Empty(t, edgeStrings(g, graph.Root))
+
+	// Stale keys are ignored.
+	g.DeleteEdgeKey(e1)
+	require.ElementsMatch(t, []string{
+		"1->2(2)",
+		"2->3(1)",
+		"2->3(1)",
+	}, edgeStrings(dup, graph.Root))
+}
+
+func TestGraphUndirectedMultigraph(t *testing.T) {
+	var (
+		g  = graph.NewUndirectedMultigraph()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	require.False(t, g.IsDirected())
+	require.True(t, g.IsMultigraph())
+
+	e1, _ := g.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 1})
+	g.AddEdgeCost(k2, k1, 2)
+	g.AddEdgeCost(k1, k1, 3)
+
+	require.ElementsMatch(t, []string{
+		"1->2(1)",
+		"1->2(2)",
+		"1->1(3)",
+	}, edgeStrings(g, graph.Root))
+	require.ElementsMatch(t, []string{
+		"2->1(1)",
+		"2->1(2)",
+	}, edgeStrings(g, k2))
+
+	g.DeleteEdgeKey(e1)
+	require.ElementsMatch(t, []string{"1->2(2)", "1->1(3)"}, edgeStrings(g, k1))
+	require.ElementsMatch(t, []string{"2->1(2)"}, edgeStrings(g, k2))
+
+	forest := g.MinimumSpanningForest()
+	require.True(t, forest.IsMultigraph())
+	require.ElementsMatch(t, []string{"1->2(2)"}, edgeStrings(forest, graph.Root))
+}
+
+func TestGraphEdgeKeySimple(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	key, ok := g.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 1})
+	require.True(t, ok)
+	require.Equal(t, graph.EdgeKey{}, key)
+
+	// Without parallel edges, adding an edge again replaces it.
+	g.AddEdgeCost(k1, k2, 2)
+	require.ElementsMatch(t, []string{"1->2(2)"}, edgeStrings(g, graph.Root))
+}
+
+func TestGraphSetVertexValue(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	g.AddEdge(k1, k2)
+
+	require.True(t, g.SetVertexValue(k1, 10))
+	require.False(t, g.SetVertexValue(graph.Key{}, 10))
+	vertex := get(t, g, k1)
+	require.Equal(t, 10, vertex.Value())
+	require.Equal(t, []string{"10->2(1)"}, edgeStrings(g, graph.Root))
+}
+
+func TestGraphVertexIndex(t *testing.T) {
+	var (
+		g = graph.New(graph.WithVertexIndex(func(value interface{}) string {
+			return fmt.Sprint(value)
+		}))
+		k1 = g.AddVertex("a")
+		k2 = g.AddVertex("b")
+		k3 = g.AddVertex("c")
+	)
+
+	requireLookup := func(
+		t *testing.T,
+		g *graph.Graph,
+		index string,
+		key graph.Key,
+	) {
+		actual, ok := g.Lookup(index)
+		require.True(t, ok)
+		require.Equal(t, key, actual)
+	}
+
+	requireLookup(t, g, "a", k1)
+	requireLookup(t, g, "b", k2)
+
+	_, ok := g.Lookup("d")
+	require.False(t, ok)
+
+	// Updating a value reindexes its vertex.
+	g.SetVertexValue(k1, "d")
+	requireLookup(t, g, "d", k1)
+
+	_, ok = g.Lookup("a")
+	require.False(t, ok)
+
+	// Copies maintain their own indexes.
+	dup := g.FilterVertices(func(vertex graph.Vertex) bool {
+		return vertex.Value() != "b"
+	})
+	g.DeleteVertex(k3)
+
+	_, ok = g.Lookup("c")
+	require.False(t, ok)
+	requireLookup(t, g, "b", k2)
+
+	_, ok = dup.Lookup("b")
+	require.False(t, ok)
+	requireLookup(t, dup, "c", k3)
+	requireLookup(t, dup, "d", k1)
+
+	// The most recent vertex claims a shared index key, and deleting another
+	// vertex that shared it does not remove it.
+	k4 := g.AddVertex("b")
+	requireLookup(t, g, "b", k4)
+
+	g.DeleteVertex(k2)
+	requireLookup(t, g, "b", k4)
+
+	forest := g.MinimumSpanningForest()
+	requireLookup(t, forest, "b", k4)
+
+	// Deleting or updating the owner of a shared index key falls back to the
+	// vertex that claimed it before.
+	k5 := g.AddVertex("x")
+	k6 := g.AddVertex("x")
+	k7 := g.AddVertex("x")
+	requireLookup(t, g, "x", k7)
+
+	g.DeleteVertex(k7)
+	requireLookup(t, g, "x", k6)
+
+	g.SetVertexValue(k6, "y")
+	requireLookup(t, g, "x", k5)
+	requireLookup(t, g, "y", k6)
+
+	sub := g.FilterVertices(func(vertex graph.Vertex) bool {
+		return vertex.Key() != k5
+	})
+	_, ok = sub.Lookup("x")
+	require.False(t, ok)
+
+	g.DeleteVertex(k5)
+	_, ok = g.Lookup("x")
+	require.False(t, ok)
+
+	_, ok = graph.New().Lookup("a")
+	require.False(t, ok)
+}
+
+func edgeStrings(g *graph.Graph, key graph.Key) (edges []string) {
+	g.VisitEdges(key, func(edge graph.Edge) bool {
+		edges = append(edges, fmt.Sprintf(
+			"%v->%v(%d)",
+			edge.Start.Value(),
+			edge.End.Value(),
+			edge.Cost,
+		))
+		return true
+	})
+	return
+}
+
+func TestGraphConcurrentAccess(t *testing.T) {
+	var (
+		g    = graph.New()
+		keys = make([]graph.Key, 16)
+		wg   sync.WaitGroup
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i)
+	}
+
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				from, to := keys[(i+j)%len(keys)], keys[(i*j+1)%len(keys)]
+				g.AddEdgeCost(from, to, j)
+				g.DeleteEdge(from, to)
+			}
+		}(i)
+
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				g.Get(keys[j%len(keys)])
+				g.VisitEdges(graph.Root, func(graph.Edge) bool {
+					return true
+				})
+				g.FindPath(graph.Dijkstra, keys[0], keys[len(keys)-1])
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.Equal(t, len(keys), g.Order())
+	require.Equal(t, 0, g.Size())
+}
+
+func TestGraphVisitEdgesMutate(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		n  int
+	)
+
+	g.AddEdge(k1, k2)
+	g.AddEdge(k2, k3)
+
+	g.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		n++
+
+		_, ok := g.Get(edge.Start.Key())
+		require.True(t, ok)
+
+		g.DeleteEdge(edge.Start.Key(), edge.End.Key())
+		g.AddEdge(edge.End.Key(), edge.Start.Key())
+
+		return true
+	})
+
+	require.Equal(t, 2, n)
+	require.ElementsMatch(
+		t,
+		[]string{"2->1(1)", "3->2(1)"},
+		edgeStrings(g, graph.Root),
+	)
+}
+
+func TestGraphFilterMutate(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+	)
+
+	g.AddEdge(k1, k2)
+	g.AddEdge(k2, k3)
+
+	dup := g.FilterEdges(func(edge graph.Edge) bool {
+		g.DeleteEdge(edge.Start.Key(), edge.End.Key())
+		return edge.Start.Key() == k1
+	})
+
+	require.Equal(t, 0, g.Size())
+	require.Equal(t, []string{"1->2(1)"}, edgeStrings(dup, graph.Root))
+
+	filtered := dup.FilterVertices(func(vertex graph.Vertex) bool {
+		dup.DeleteVertex(vertex.Key())
+		return vertex.Key() != k3
+	})
+
+	require.Equal(t, 0, dup.Order())
+	require.Equal(t, 2, filtered.Order())
+	require.Equal(t, []string{"1->2(1)"}, edgeStrings(filtered, graph.Root))
+}
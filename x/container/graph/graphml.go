@@ -0,0 +1,317 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+const (
+	_graphMLMultigraph = "multigraph"
+	_graphMLValue      = "value"
+	_graphMLCost       = "cost"
+	_graphMLWeight     = "weight"
+	_graphMLLabel      = "label"
+	_graphMLMetadata   = "metadata"
+)
+
+// _graphMLKeys declares the attributes written by WriteGraphML.
+var _graphMLKeys = []graphMLKey{
+	{ID: _graphMLMultigraph, For: "graph", Name: "multigraph", Type: "boolean"},
+	{ID: _graphMLValue, For: "node", Name: "value", Type: "string"},
+	{ID: _graphMLCost, For: "edge", Name: "cost", Type: "int"},
+	{ID: _graphMLWeight, For: "edge", Name: "weight", Type: "double"},
+	{ID: _graphMLLabel, For: "edge", Name: "label", Type: "string"},
+	{ID: _graphMLMetadata, For: "edge", Name: "metadata", Type: "string"},
+}
+
+type graphMLDocument struct {
+	XMLName xml.Name     `xml:"http://graphml.graphdrawing.org/xmlns graphml"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Data        []graphMLData `xml:"data"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr,omitempty"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML writes g to w as a GraphML document. The document preserves the
+// keys, edges, and directedness of g, and includes the value of each vertex as
+// encoded by g's ValueCodec. Edge metadata is encoded as JSON.
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	sg, err := g.serialize()
+	if err != nil {
+		return err
+	}
+
+	doc := graphMLDocument{
+		Keys: _graphMLKeys,
+		Graph: graphMLGraph{
+			EdgeDefault: "directed",
+			Nodes:       make([]graphMLNode, len(sg.vertices)),
+			Edges:       make([]graphMLEdge, len(sg.edges)),
+		},
+	}
+
+	if !sg.directed {
+		doc.Graph.EdgeDefault = "undirected"
+	}
+
+	if sg.multigraph {
+		doc.Graph.Data = []graphMLData{{Key: _graphMLMultigraph, Value: "true"}}
+	}
+
+	for i, vertex := range sg.vertices {
+		doc.Graph.Nodes[i] = graphMLNode{
+			ID:   formatKey(vertex.key),
+			Data: []graphMLData{{Key: _graphMLValue, Value: string(vertex.value)}},
+		}
+	}
+
+	for i, edge := range sg.edges {
+		if doc.Graph.Edges[i], err = newGraphMLEdge(edge); err != nil {
+			return err
+		}
+	}
+
+	if _, err = io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+
+	if err = enc.Encode(doc); err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// ReadGraphML parses a GraphML document, such as one written by WriteGraphML,
+// from r and returns it as a new graph constructed with opts. Vertex values are
+// decoded with the graph's ValueCodec.
+//
+// Node IDs must be valid keys (i.e. positive integers), and are used as the
+// keys of their vertices. Likewise, the edge IDs of a multigraph must be valid
+// keys, and are used as the EdgeKeys of their edges; other graphs' edge IDs are
+// ignored. Edges without a cost attribute have a cost of 1.
+// Attributes other than those written by WriteGraphML are ignored.
+func ReadGraphML(r io.Reader, opts ...Option) (*Graph, error) {
+	var doc graphMLDocument
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	sg := &serialGraph{
+		directed: doc.Graph.EdgeDefault != "undirected",
+		vertices: make([]serialVertex, len(doc.Graph.Nodes)),
+		edges:    make([]serialEdge, len(doc.Graph.Edges)),
+	}
+
+	for _, data := range doc.Graph.Data {
+		if data.Key == _graphMLMultigraph {
+			sg.multigraph = data.Value == "true"
+		}
+	}
+
+	var err error
+
+	for i, node := range doc.Graph.Nodes {
+		if sg.vertices[i], err = node.serialVertex(); err != nil {
+			return nil, err
+		}
+	}
+
+	for i, edge := range doc.Graph.Edges {
+		if sg.edges[i], err = edge.serialEdge(sg.multigraph); err != nil {
+			return nil, err
+		}
+	}
+
+	g := New(opts...)
+	if err = g.deserialize(sg); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+func newGraphMLEdge(edge serialEdge) (graphMLEdge, error) {
+	var (
+		attrs = edge.data.attrs()
+		ml    = graphMLEdge{
+			Source: formatKey(edge.from),
+			Target: formatKey(edge.to),
+			Data: []graphMLData{{
+				Key:   _graphMLCost,
+				Value: strconv.Itoa(attrs.Cost),
+			}},
+		}
+	)
+
+	if edge.data.key != 0 {
+		ml.ID = formatKey(edge.data.key)
+	}
+
+	if attrs.Weight != 0 {
+		ml.addData(
+			_graphMLWeight,
+			strconv.FormatFloat(attrs.Weight, 'g', -1, 64),
+		)
+	}
+
+	if attrs.Label != "" {
+		ml.addData(_graphMLLabel, attrs.Label)
+	}
+
+	if len(attrs.Metadata) > 0 {
+		metadata, err := json.Marshal(attrs.Metadata)
+		if err != nil {
+			return ml, fmt.Errorf("edge %v->%v: %w", edge.from, edge.to, err)
+		}
+
+		ml.addData(_graphMLMetadata, string(metadata))
+	}
+
+	return ml, nil
+}
+
+func (e *graphMLEdge) addData(key string, value string) {
+	e.Data = append(e.Data, graphMLData{Key: key, Value: value})
+}
+
+func (e *graphMLEdge) serialEdge(multigraph bool) (serialEdge, error) {
+	var (
+		edge serialEdge
+		err  error
+	)
+
+	if edge.from, err = parseKey(e.Source); err != nil {
+		return edge, err
+	}
+
+	if edge.to, err = parseKey(e.Target); err != nil {
+		return edge, err
+	}
+
+	attrs, err := e.attrs()
+	if err != nil {
+		return edge, fmt.Errorf("edge %v->%v: %w", edge.from, edge.to, err)
+	}
+
+	edge.data = newEdgeData(attrs)
+
+	// Only multigraph edges are keyed.
+	if multigraph && e.ID != "" {
+		if edge.data.key, err = parseKey(e.ID); err != nil {
+			return edge, err
+		}
+	}
+
+	return edge, nil
+}
+
+func (e *graphMLEdge) attrs() (EdgeAttrs, error) {
+	var (
+		attrs = EdgeAttrs{Cost: 1}
+		err   error
+	)
+
+	for _, data := range e.Data {
+		switch data.Key {
+		case _graphMLCost:
+			attrs.Cost, err = strconv.Atoi(data.Value)
+		case _graphMLWeight:
+			attrs.Weight, err = strconv.ParseFloat(data.Value, 64)
+		case _graphMLLabel:
+			attrs.Label = data.Value
+		case _graphMLMetadata:
+			err = json.Unmarshal([]byte(data.Value), &attrs.Metadata)
+		}
+
+		if err != nil {
+			return attrs, err
+		}
+	}
+
+	return attrs, nil
+}
+
+func (n *graphMLNode) serialVertex() (serialVertex, error) {
+	key, err := parseKey(n.ID)
+	if err != nil {
+		return serialVertex{}, err
+	}
+
+	vertex := serialVertex{key: key}
+	for _, data := range n.Data {
+		if data.Key == _graphMLValue {
+			vertex.value = []byte(data.Value)
+		}
+	}
+
+	return vertex, nil
+}
+
+func formatKey(key internal.Key) string {
+	return strconv.FormatUint(uint64(key), 10)
+}
+
+func parseKey(text string) (internal.Key, error) {
+	var key Key
+	err := key.UnmarshalText([]byte(text))
+	return key.key, err
+}
@@ -0,0 +1,254 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteGraphML(t *testing.T) {
+	var (
+		g  = graph.NewUndirectedMultigraph(graph.WithValueCodec(textCodec{}))
+		k1 = g.AddVertex("a")
+		k2 = g.AddVertex("b & c")
+	)
+
+	g.AddEdgeWith(k2, k1, graph.EdgeAttrs{
+		Cost:     3,
+		Weight:   0.25,
+		Label:    "uplink",
+		Metadata: map[string]interface{}{"protocol": "tcp"},
+	})
+	g.AddEdgeCost(k1, k1, 1)
+
+	var buf bytes.Buffer
+	require.NoError(t, g.WriteGraphML(&buf))
+	require.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+  <key id="multigraph" for="graph" attr.name="multigraph" `+
+		`attr.type="boolean"></key>
+  <key id="value" for="node" attr.name="value" attr.type="string"></key>
+  <key id="cost" for="edge" attr.name="cost" attr.type="int"></key>
+  <key id="weight" for="edge" attr.name="weight" attr.type="double"></key>
+  <key id="label" for="edge" attr.name="label" attr.type="string"></key>
+  <key id="metadata" for="edge" attr.name="metadata" attr.type="string"></key>
+  <graph edgedefault="undirected">
+    <data key="multigraph">true</data>
+    <node id="1">
+      <data key="value">a</data>
+    </node>
+    <node id="2">
+      <data key="value">b &amp; c</data>
+    </node>
+    <edge id="2" source="1" target="1">
+      <data key="cost">1</data>
+    </edge>
+    <edge id="1" source="1" target="2">
+      <data key="cost">3</data>
+      <data key="weight">0.25</data>
+      <data key="label">uplink</data>
+      <data key="metadata">{&#34;protocol&#34;:&#34;tcp&#34;}</data>
+    </edge>
+  </graph>
+</graphml>
+`, buf.String())
+
+	dup, err := graph.ReadGraphML(&buf, graph.WithValueCodec(textCodec{}))
+	require.NoError(t, err)
+	require.False(t, dup.IsDirected())
+	require.True(t, dup.IsMultigraph())
+	require.ElementsMatch(
+		t,
+		edgeStrings(g, graph.Root),
+		edgeStrings(dup, graph.Root),
+	)
+
+	require.Equal(t, g.Size(), dup.Size())
+	dup.VisitEdges(k2, func(edge graph.Edge) bool {
+		require.Equal(t, graph.EdgeAttrs{
+			Cost:     3,
+			Weight:   0.25,
+			Label:    "uplink",
+			Metadata: map[string]interface{}{"protocol": "tcp"},
+		}, edge.Attrs())
+		return true
+	})
+}
+
+func TestReadGraphMLRoundTrip(t *testing.T) {
+	for _, g := range []*graph.Graph{graph.New(), graph.NewUndirected()} {
+		var (
+			k1 = g.AddVertex(1.5)
+			k2 = g.AddVertex("b")
+			k3 = g.AddVertex(true)
+		)
+
+		g.AddEdgeCost(k1, k3, 2)
+		g.AddEdgeCost(k3, k2, -1)
+		g.DeleteVertex(k2)
+
+		var buf bytes.Buffer
+		require.NoError(t, g.WriteGraphML(&buf))
+
+		dup, err := graph.ReadGraphML(&buf)
+		require.NoError(t, err)
+		require.Equal(t, g.IsDirected(), dup.IsDirected())
+		require.False(t, dup.IsMultigraph())
+		require.Equal(t, g.Order(), dup.Order())
+		require.Equal(t, g.Size(), dup.Size())
+		require.ElementsMatch(
+			t,
+			edgeStrings(g, graph.Root),
+			edgeStrings(dup, graph.Root),
+		)
+
+		_, ok := dup.Get(k2)
+		require.False(t, ok)
+	}
+}
+
+func TestReadGraphMLErrors(t *testing.T) {
+	const header = `<graphml xmlns="http://graphml.graphdrawing.org/xmlns">`
+
+	cases := []struct {
+		name string
+		give string
+		key  bool
+	}{
+		{
+			name: "syntax",
+			give: header + `<graph>`,
+		},
+		{
+			name: "namespace",
+			give: `<graphml><graph></graph></graphml>`,
+		},
+		{
+			name: "node id",
+			give: header + `<graph><node id="n0"/></graph></graphml>`,
+			key:  true,
+		},
+		{
+			name: "edge source",
+			give: header + `<graph>
+				<node id="1"/>
+				<edge source="n0" target="1"/>
+			</graph></graphml>`,
+			key: true,
+		},
+		{
+			name: "edge target",
+			give: header + `<graph>
+				<node id="1"/>
+				<edge source="1" target="2"/>
+			</graph></graphml>`,
+			key: true,
+		},
+		{
+			name: "edge id",
+			give: header + `<graph>
+				<data key="multigraph">true</data>
+				<node id="1"/>
+				<edge id="e0" source="1" target="1"/>
+			</graph></graphml>`,
+			key: true,
+		},
+		{
+			name: "cost",
+			give: header + `<graph>
+				<node id="1"/>
+				<edge source="1" target="1"><data key="cost">x</data></edge>
+			</graph></graphml>`,
+		},
+		{
+			name: "weight",
+			give: header + `<graph>
+				<node id="1"/>
+				<edge source="1" target="1"><data key="weight">x</data></edge>
+			</graph></graphml>`,
+		},
+		{
+			name: "metadata",
+			give: header + `<graph>
+				<node id="1"/>
+				<edge source="1" target="1"><data key="metadata">x</data></edge>
+			</graph></graphml>`,
+		},
+		{
+			name: "value",
+			give: header + `<graph>
+				<node id="1"><data key="value">x</data></node>
+			</graph></graphml>`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := graph.ReadGraphML(strings.NewReader(tt.give))
+			require.Nil(t, g)
+			require.Error(t, err)
+			require.Equal(t, tt.key, errors.Is(err, graph.ErrInvalidKey), "%v", err)
+		})
+	}
+}
+
+func TestReadGraphMLDefaults(t *testing.T) {
+	g, err := graph.ReadGraphML(strings.NewReader(`
+		<graphml xmlns="http://graphml.graphdrawing.org/xmlns">
+			<graph edgedefault="directed">
+				<node id="3"/>
+				<node id="5"><data key="value">"x"</data></node>
+				<edge id="e0" source="3" target="5">
+					<data key="color">red</data>
+				</edge>
+			</graph>
+		</graphml>
+	`))
+	require.NoError(t, err)
+	require.True(t, g.IsDirected())
+	require.Equal(t, []string{"<nil>->x(1)"}, edgeStrings(g, graph.Root))
+}
+
+func TestWriteGraphMLError(t *testing.T) {
+	g := graph.New(graph.WithValueCodec(pointCodec{}))
+	g.AddVertex("not a point")
+
+	require.Error(t, g.WriteGraphML(&bytes.Buffer{}))
+
+	g = graph.New()
+	k1 := g.AddVertex(1)
+	g.AddEdgeWith(k1, k1, graph.EdgeAttrs{
+		Metadata: map[string]interface{}{"fn": func() {}},
+	})
+
+	require.Error(t, g.WriteGraphML(&bytes.Buffer{}))
+
+	err := errors.New("write failed")
+	require.Equal(t, err, g.FilterEdges(func(graph.Edge) bool {
+		return false
+	}).WriteGraphML(failingWriter{err: err}))
+}
@@ -0,0 +1,104 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package internal
+
+import (
+	"container/heap"
+)
+
+// Costed is a vertex, identified by either its key or its index (e.g. within
+// a snapshot of a graph), along with the cost of reaching it.
+type Costed struct {
+	Key   Key
+	Index int
+	Cost  int
+}
+
+// costs is a heap.Interface of Costed vertices, ordered by cost, and then by
+// key and index.
+type costs []Costed
+
+func (c costs) Len() int {
+	return len(c)
+}
+
+func (c costs) Less(i int, j int) bool {
+	switch {
+	case c[i].Cost != c[j].Cost:
+		return c[i].Cost < c[j].Cost
+	case c[i].Key != c[j].Key:
+		return c[i].Key < c[j].Key
+	default:
+		return c[i].Index < c[j].Index
+	}
+}
+
+func (c costs) Swap(i int, j int) {
+	c[i], c[j] = c[j], c[i]
+}
+
+func (c *costs) Push(x interface{}) {
+	*c = append(*c, x.(Costed))
+}
+
+func (c *costs) Pop() interface{} {
+	var (
+		deref = *c
+		n     = len(deref)
+		x     = deref[n-1]
+	)
+
+	*c = deref[:n-1]
+
+	return x
+}
+
+// CostHeap is a min-heap of Costed vertices, ordered by cost, and then by key
+// and index.
+type CostHeap struct {
+	costs costs
+}
+
+// NewCostHeap creates a new CostHeap, initialized to contain vertices.
+func NewCostHeap(vertices ...Costed) *CostHeap {
+	h := &CostHeap{
+		costs: append(costs(nil), vertices...),
+	}
+
+	heap.Init(&h.costs)
+
+	return h
+}
+
+// Len returns the heap's length.
+func (h *CostHeap) Len() int {
+	return h.costs.Len()
+}
+
+// Push pushes vertex onto the heap.
+func (h *CostHeap) Push(vertex Costed) {
+	heap.Push(&h.costs, vertex)
+}
+
+// Pop removes and returns the cheapest vertex in h.
+func (h *CostHeap) Pop() Costed {
+	return heap.Pop(&h.costs).(Costed)
+}
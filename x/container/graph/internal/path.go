@@ -27,10 +27,15 @@ import (
 // Key represents a graph vertex.
 type Key uint64
 
-// Path represents an ordered series of contiguously incident vertices.
+// Path represents an ordered series of contiguously incident vertices. Estimate
+// is an optional heuristic of the remaining cost to a destination, and affects
+// only the ordering of paths.
 type Path struct {
 	Cost     int
+	Estimate int
 	Vertices []Key // TODO(mway): list
+
+	seq uint64 // order of insertion into a PathHeap, for breaking ties
 }
 
 // Extend extends p to contain vertex as the latest incident vertex and
@@ -55,9 +60,18 @@ func (p Paths) Len() int {
 }
 
 // Less returns true if the cost of the ith Path in p is less than the cost of
-// the jth path.
+// the jth path. A path's cost includes its estimated remaining cost, if any.
 func (p Paths) Less(i int, j int) bool {
-	return p[i].Cost < p[j].Cost
+	var (
+		ci = p[i].Cost + p[i].Estimate
+		cj = p[j].Cost + p[j].Estimate
+	)
+
+	if ci != cj {
+		return ci < cj
+	}
+
+	return p[i].seq < p[j].seq
 }
 
 // Swap swaps the ith and jth paths in p.
@@ -89,6 +103,7 @@ func (p *Paths) Pop() interface{} {
 // PathHeap is a convenience wrapper around Paths and heap.Interface.
 type PathHeap struct {
 	paths *Paths
+	seq   uint64
 }
 
 // NewPathHeap creates a new PathHeap, initialized to contain paths.
@@ -113,6 +128,8 @@ func (p *PathHeap) Len() int {
 
 // Push pushes path onto the heap.
 func (p *PathHeap) Push(path Path) {
+	path.seq = p.seq
+	p.seq++
 	heap.Push(p.paths, path)
 }
 
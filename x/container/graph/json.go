@@ -0,0 +1,131 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"encoding/json"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+type jsonGraph struct {
+	Directed   bool         `json:"directed"`
+	Multigraph bool         `json:"multigraph,omitempty"`
+	Vertices   []jsonVertex `json:"vertices"`
+	Edges      []jsonEdge   `json:"edges"`
+}
+
+type jsonVertex struct {
+	Key   Key             `json:"key"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+type jsonEdge struct {
+	Key      uint64                 `json:"key,omitempty"`
+	From     Key                    `json:"from"`
+	To       Key                    `json:"to"`
+	Cost     int                    `json:"cost"`
+	Weight   float64                `json:"weight,omitempty"`
+	Label    string                 `json:"label,omitempty"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler. The resulting JSON preserves the keys,
+// edges, and directedness of g, and includes the value of each vertex as
+// encoded by g's ValueCodec, which must produce valid JSON.
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	sg, err := g.serialize()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := jsonGraph{
+		Directed:   sg.directed,
+		Multigraph: sg.multigraph,
+		Vertices:   make([]jsonVertex, len(sg.vertices)),
+		Edges:      make([]jsonEdge, len(sg.edges)),
+	}
+
+	for i, vertex := range sg.vertices {
+		doc.Vertices[i] = jsonVertex{
+			Key:   newKey(vertex.key),
+			Value: vertex.value,
+		}
+	}
+
+	for i, edge := range sg.edges {
+		attrs := edge.data.attrs()
+		doc.Edges[i] = jsonEdge{
+			Key:      uint64(edge.data.key),
+			From:     newKey(edge.from),
+			To:       newKey(edge.to),
+			Cost:     attrs.Cost,
+			Weight:   attrs.Weight,
+			Label:    attrs.Label,
+			Metadata: attrs.Metadata,
+		}
+	}
+
+	return json.Marshal(doc)
+}
+
+// UnmarshalJSON implements json.Unmarshaler, replacing the contents of g with
+// the graph encoded in data. Vertex values are decoded with g's ValueCodec, and
+// the options g was constructed with are retained. If data cannot be decoded,
+// g is left unchanged.
+func (g *Graph) UnmarshalJSON(data []byte) error {
+	var doc jsonGraph
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+
+	sg := &serialGraph{
+		directed:   doc.Directed,
+		multigraph: doc.Multigraph,
+		vertices:   make([]serialVertex, len(doc.Vertices)),
+		edges:      make([]serialEdge, len(doc.Edges)),
+	}
+
+	for i, vertex := range doc.Vertices {
+		sg.vertices[i] = serialVertex{
+			key:   vertex.Key.key,
+			value: vertex.Value,
+		}
+	}
+
+	for i, edge := range doc.Edges {
+		data := newEdgeData(EdgeAttrs{
+			Cost:     edge.Cost,
+			Weight:   edge.Weight,
+			Label:    edge.Label,
+			Metadata: edge.Metadata,
+		})
+		data.key = internal.Key(edge.Key)
+
+		sg.edges[i] = serialEdge{
+			from: edge.From.key,
+			to:   edge.To.key,
+			data: data,
+		}
+	}
+
+	return g.deserialize(sg)
+}
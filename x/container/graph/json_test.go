@@ -0,0 +1,330 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGraphMarshalJSON(t *testing.T) {
+	var (
+		g  = graph.NewMultigraph()
+		k1 = g.AddVertex("a")
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(nil)
+	)
+
+	g.AddEdgeWith(k1, k2, graph.EdgeAttrs{
+		Cost:     3,
+		Weight:   0.5,
+		Label:    "uplink",
+		Metadata: map[string]interface{}{"protocol": "tcp"},
+	})
+	g.AddEdgeCost(k1, k2, 4)
+	g.AddEdgeCost(k3, k1, -1)
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"directed": true,
+		"multigraph": true,
+		"vertices": [
+			{"key": 1, "value": "a"},
+			{"key": 2, "value": 2},
+			{"key": 3, "value": null}
+		],
+		"edges": [
+			{
+				"key": 1,
+				"from": 1,
+				"to": 2,
+				"cost": 3,
+				"weight": 0.5,
+				"label": "uplink",
+				"metadata": {"protocol": "tcp"}
+			},
+			{"key": 2, "from": 1, "to": 2, "cost": 4},
+			{"key": 3, "from": 3, "to": 1, "cost": -1}
+		]
+	}`, string(data))
+}
+
+func TestGraphUnmarshalJSON(t *testing.T) {
+	for _, g := range []*graph.Graph{
+		graph.New(),
+		graph.NewUndirected(),
+		graph.NewMultigraph(),
+		graph.NewUndirectedMultigraph(),
+	} {
+		var (
+			k1 = g.AddVertex("a")
+			k2 = g.AddVertex("b")
+			k3 = g.AddVertex("c")
+			k4 = g.AddVertex("d")
+		)
+
+		g.AddEdgeWith(k1, k2, graph.EdgeAttrs{Cost: 2, Label: "x"})
+		g.AddEdgeCost(k1, k2, 3)
+		g.AddEdgeCost(k4, k1, 1)
+		g.AddEdgeCost(k4, k4, 1)
+
+		// Deleting a vertex leaves a gap in the keys, which must be preserved.
+		g.DeleteVertex(k3)
+
+		data, err := json.Marshal(g)
+		require.NoError(t, err)
+
+		dup := graph.New(graph.WithVertexIndex(func(value interface{}) string {
+			return fmt.Sprint(value)
+		}))
+		require.NoError(t, json.Unmarshal(data, dup))
+
+		require.Equal(t, g.IsDirected(), dup.IsDirected())
+		require.Equal(t, g.IsMultigraph(), dup.IsMultigraph())
+		require.Equal(t, g.Order(), dup.Order())
+		require.Equal(t, g.Size(), dup.Size())
+		require.ElementsMatch(
+			t,
+			edgeStrings(g, graph.Root),
+			edgeStrings(dup, graph.Root),
+		)
+		require.Equal(t, k4, lookup(t, dup, "d"))
+
+		_, ok := dup.Get(k3)
+		require.False(t, ok)
+
+		// New vertices and edges do not reuse existing keys.
+		k5 := dup.AddVertex("e")
+		require.NotEqual(t, k4, k5)
+
+		key, _ := dup.AddEdgeKey(k5, k1, graph.EdgeAttrs{})
+		dup.VisitEdges(k1, func(edge graph.Edge) bool {
+			if dup.IsMultigraph() && edge.End.Key() != k5 {
+				require.NotEqual(t, key, edge.Key())
+			}
+			return true
+		})
+
+		// Re-encoding the copy produces the same document.
+		dup.DeleteVertex(k5)
+		redata, err := json.Marshal(dup)
+		require.NoError(t, err)
+		require.JSONEq(t, string(data), string(redata))
+	}
+}
+
+func TestGraphUnmarshalJSONCodec(t *testing.T) {
+	var (
+		codec = pointCodec{}
+		g     = graph.New(graph.WithValueCodec(codec))
+		k1    = g.AddVertex(point{x: 1, y: 2})
+		k2    = g.AddVertex(point{x: 3, y: 4})
+	)
+
+	g.AddEdge(k1, k2)
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	dup := graph.New(graph.WithValueCodec(codec))
+	require.NoError(t, json.Unmarshal(data, dup))
+
+	vertex := get(t, dup, k2)
+	require.Equal(t, point{x: 3, y: 4}, vertex.Value())
+
+	// Values that the codec cannot encode, or that are not JSON, fail.
+	g.AddVertex("not a point")
+	_, err = json.Marshal(g)
+	require.Error(t, err)
+
+	g = graph.New(graph.WithValueCodec(textCodec{}))
+	g.AddVertex("text")
+	_, err = json.Marshal(g)
+	require.Error(t, err)
+}
+
+func TestGraphUnmarshalJSONErrors(t *testing.T) {
+	cases := []struct {
+		name string
+		give string
+		key  bool
+	}{
+		{
+			name: "syntax",
+			give: `{"vertices": [`,
+		},
+		{
+			name: "zero key",
+			give: `{"vertices": [{"key": 0}]}`,
+			key:  true,
+		},
+		{
+			name: "reserved key",
+			give: `{"vertices": [{"key": 18446744073709551614}]}`,
+			key:  true,
+		},
+		{
+			name: "duplicate key",
+			give: `{"vertices": [{"key": 1}, {"key": 1}]}`,
+			key:  true,
+		},
+		{
+			name: "dangling edge",
+			give: `{"vertices": [{"key": 1}], "edges": [{"from": 1, "to": 2}]}`,
+			key:  true,
+		},
+		{
+			name: "duplicate edge key",
+			give: `{
+				"multigraph": true,
+				"vertices": [{"key": 1}],
+				"edges": [
+					{"key": 1, "from": 1, "to": 1},
+					{"key": 1, "from": 1, "to": 1}
+				]
+			}`,
+			key: true,
+		},
+		{
+			name: "value",
+			give: `{"vertices": [{"key": 1, "value": "x"}]}`,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				g   = graph.New(graph.WithValueCodec(pointCodec{}))
+				key = g.AddVertex(point{})
+			)
+
+			err := json.Unmarshal([]byte(tt.give), g)
+			require.Error(t, err)
+			require.Equal(t, tt.key, errors.Is(err, graph.ErrInvalidKey), "%v", err)
+
+			// The graph is unchanged.
+			require.Equal(t, 1, g.Order())
+			_, ok := g.Get(key)
+			require.True(t, ok)
+		})
+	}
+}
+
+func TestGraphUnmarshalJSONDefaults(t *testing.T) {
+	var g graph.Graph
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"multigraph": true,
+		"vertices": [{"key": 2, "value": 1.5}, {"key": 1}],
+		"edges": [{"from": 1, "to": 2, "cost": 1}, {"from": 1, "to": 2}]
+	}`), &g))
+
+	require.False(t, g.IsDirected())
+	require.True(t, g.IsMultigraph())
+	require.ElementsMatch(t, []string{
+		"<nil>->1.5(1)",
+		"<nil>->1.5(0)",
+	}, edgeStrings(&g, graph.Root))
+
+	var keys []graph.EdgeKey
+	g.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		keys = append(keys, edge.Key())
+		return true
+	})
+
+	require.Len(t, keys, 2)
+	require.NotEqual(t, keys[0], keys[1])
+}
+
+func TestGraphUnmarshalJSONConcurrent(t *testing.T) {
+	var (
+		g    = graph.New()
+		data = []byte(`{"directed": false, "multigraph": true}`)
+		errs = make([]error, 100)
+		wg   sync.WaitGroup
+	)
+
+	wg.Add(2)
+
+	go func() {
+		defer wg.Done()
+
+		for i := range errs {
+			errs[i] = json.Unmarshal(data, g)
+		}
+	}()
+
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			g.IsDirected()
+			g.IsMultigraph()
+		}
+	}()
+
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+
+	require.False(t, g.IsDirected())
+	require.True(t, g.IsMultigraph())
+}
+
+// pointCodec encodes points as JSON arrays.
+type pointCodec struct{}
+
+func (pointCodec) EncodeValue(value interface{}) ([]byte, error) {
+	p, ok := value.(point)
+	if !ok {
+		return nil, fmt.Errorf("not a point: %v", value)
+	}
+
+	return json.Marshal([2]int{p.x, p.y})
+}
+
+func (pointCodec) DecodeValue(data []byte) (interface{}, error) {
+	var xy [2]int
+	if err := json.Unmarshal(data, &xy); err != nil {
+		return nil, err
+	}
+
+	return point{x: xy[0], y: xy[1]}, nil
+}
+
+// textCodec encodes values as plain text.
+type textCodec struct{}
+
+func (textCodec) EncodeValue(value interface{}) ([]byte, error) {
+	return []byte(fmt.Sprint(value)), nil
+}
+
+func (textCodec) DecodeValue(data []byte) (interface{}, error) {
+	return string(data), nil
+}
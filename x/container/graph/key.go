@@ -22,6 +22,7 @@ package graph
 
 import (
 	"fmt"
+	"strconv"
 
 	"github.com/mway/pkg/x/container/graph/internal"
 )
@@ -40,3 +41,56 @@ func newKey(key internal.Key) Key {
 func (k Key) String() string {
 	return fmt.Sprintf("%v", k.key)
 }
+
+// MarshalText implements encoding.TextMarshaler. Keys are encoded as decimal
+// numbers.
+func (k Key) MarshalText() ([]byte, error) {
+	return strconv.AppendUint(nil, uint64(k.key), 10), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (k *Key) UnmarshalText(text []byte) error {
+	key, err := strconv.ParseUint(string(text), 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, text)
+	}
+
+	k.key = internal.Key(key)
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. Keys are encoded as JSON numbers.
+func (k Key) MarshalJSON() ([]byte, error) {
+	return k.MarshalText()
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Keys may be decoded from either
+// JSON numbers or JSON strings.
+func (k *Key) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	if len(data) > 1 && data[0] == '"' && data[len(data)-1] == '"' {
+		data = data[1 : len(data)-1]
+	}
+
+	return k.UnmarshalText(data)
+}
+
+// EdgeKey is a thin identifier for the edges of a multigraph. Edges of graphs
+// that are not multigraphs are identified by their incident vertices alone, and
+// have a zero EdgeKey.
+type EdgeKey struct {
+	key internal.Key
+}
+
+func newEdgeKey(key internal.Key) EdgeKey {
+	return EdgeKey{
+		key: key,
+	}
+}
+
+func (k EdgeKey) String() string {
+	return fmt.Sprintf("%v", k.key)
+}
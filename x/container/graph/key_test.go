@@ -0,0 +1,70 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyMarshal(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	type doc struct {
+		Key   graph.Key            `json:"key"`
+		Costs map[graph.Key]string `json:"costs"`
+	}
+
+	data, err := json.Marshal(doc{
+		Key:   k1,
+		Costs: map[graph.Key]string{k1: "a", k2: "b"},
+	})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"key":1,"costs":{"1":"a","2":"b"}}`, string(data))
+
+	var actual doc
+	require.NoError(t, json.Unmarshal(data, &actual))
+	require.Equal(t, k1, actual.Key)
+	require.Equal(t, map[graph.Key]string{k1: "a", k2: "b"}, actual.Costs)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"key":"2"}`), &actual))
+	require.Equal(t, k2, actual.Key)
+
+	require.NoError(t, json.Unmarshal([]byte(`{"key":null}`), &actual))
+	require.Equal(t, k2, actual.Key)
+
+	text, err := k2.MarshalText()
+	require.NoError(t, err)
+	require.Equal(t, "2", string(text))
+
+	for _, give := range []string{`{"key":-1}`, `{"key":"x"}`, `{"key":1.5}`} {
+		err := json.Unmarshal([]byte(give), &actual)
+		require.True(t, errors.Is(err, graph.ErrInvalidKey), "%v", err)
+	}
+}
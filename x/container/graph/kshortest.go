@@ -0,0 +1,256 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"context"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// KShortest returns a FindPathsFunc that uses Yen's algorithm to find up to k
+// loopless paths spanning two vertices. Paths are ordered by ascending cost;
+// paths of equal cost are returned in the order they are discovered.
+//
+// Edge costs must not be negative.
+func KShortest(k int) FindPathsFunc {
+	return KShortestBy(k, edgeCost)
+}
+
+// KShortestBy returns a FindPathsFunc that behaves identically to KShortest,
+// but uses cost to determine the cost of each edge rather than Edge.Cost.
+func KShortestBy(k int, cost EdgeCostFunc) FindPathsFunc {
+	return func(g *Graph, from Key, to Key) Paths {
+		return kShortest(g, from.key, to.key, k, cost)
+	}
+}
+
+// KShortestContext returns a FindPathsContextFunc that behaves identically to
+// KShortest, but stops searching once its context is done. If the search is
+// stopped, the context's error is returned along with the paths found so far,
+// which are the cheapest (in order) of the paths spanning the two vertices.
+func KShortestContext(k int) FindPathsContextFunc {
+	return KShortestByContext(k, edgeCost)
+}
+
+// KShortestByContext returns a FindPathsContextFunc that behaves identically to
+// KShortestContext, but uses cost to determine the cost of each edge rather
+// than Edge.Cost.
+func KShortestByContext(k int, cost EdgeCostFunc) FindPathsContextFunc {
+	return func(
+		ctx context.Context,
+		g *Graph,
+		from Key,
+		to Key,
+	) (Paths, error) {
+		paths, ok := kShortestUntil(ctx.Done(), g, from.key, to.key, k, cost)
+		if !ok {
+			return paths, ctx.Err()
+		}
+
+		return paths, nil
+	}
+}
+
+func kShortest(
+	src edgeSource,
+	from internal.Key,
+	to internal.Key,
+	k int,
+	cost EdgeCostFunc,
+) Paths {
+	paths, _ := kShortestUntil(nil, src, from, to, k, cost)
+	return paths
+}
+
+// kShortestUntil behaves like kShortest, but stops once done is closed,
+// returning false along with the paths found so far.
+func kShortestUntil(
+	done <-chan struct{},
+	src edgeSource,
+	from internal.Key,
+	to internal.Key,
+	k int,
+	cost EdgeCostFunc,
+) (Paths, bool) {
+	if k <= 0 {
+		return nil, true
+	}
+
+	search := yenSearch{
+		src:  src,
+		to:   to,
+		cost: cost,
+		done: done,
+	}
+
+	found, ok := search.run(from, k)
+	if len(found) == 0 {
+		return nil, ok
+	}
+
+	paths := make(Paths, len(found))
+	for i, path := range found {
+		paths[i] = newPathFromInternal(path)
+	}
+
+	return paths, ok
+}
+
+// yenSearch is the state of a single search for the k shortest paths. If done
+// is closed, the search stops, and stopped is set.
+type yenSearch struct {
+	src     edgeSource
+	to      internal.Key
+	cost    EdgeCostFunc
+	paths   []internal.Path
+	done    <-chan struct{}
+	stopped bool
+}
+
+func (y *yenSearch) run(from internal.Key, k int) ([]internal.Path, bool) {
+	first, ok := dijkstraUntil(y.done, y.src, from, y.to, nil, y.cost)
+	if !ok {
+		return nil, false
+	}
+
+	if len(first.Vertices) == 0 {
+		return nil, true
+	}
+
+	var (
+		candidates = internal.NewPathHeap()
+		seen       = map[string]struct{}{pathID(first): {}}
+	)
+
+	y.paths = []internal.Path{first}
+
+	for len(y.paths) < k {
+		prev := y.paths[len(y.paths)-1]
+
+		for i := 0; i < len(prev.Vertices)-1 && !y.stopped; i++ {
+			spur, ok := y.spur(prev, i)
+			if !ok {
+				continue
+			}
+
+			id := pathID(spur)
+			if _, dup := seen[id]; dup {
+				continue
+			}
+
+			seen[id] = struct{}{}
+			candidates.Push(spur)
+		}
+
+		if y.stopped {
+			return y.paths, false
+		}
+
+		if candidates.Len() == 0 {
+			break
+		}
+
+		y.paths = append(y.paths, candidates.Pop())
+	}
+
+	return y.paths, true
+}
+
+// spur computes the candidate path that deviates from prev at its ith vertex,
+// excluding any deviations that have already been accepted.
+func (y *yenSearch) spur(prev internal.Path, i int) (internal.Path, bool) {
+	var (
+		root    = prev.Vertices[:i+1]
+		spur    = root[i]
+		removed = make(map[[2]internal.Key]struct{})
+		blocked = make(map[internal.Key]struct{}, i)
+	)
+
+	for _, path := range y.paths {
+		if len(path.Vertices) > i+1 && hasPrefix(path.Vertices, root) {
+			removed[[2]internal.Key{spur, path.Vertices[i+1]}] = struct{}{}
+		}
+	}
+
+	// Vertices already in the root path may not be revisited, otherwise the
+	// resulting path would contain a loop.
+	for _, key := range root[:i] {
+		blocked[key] = struct{}{}
+	}
+
+	tail, ok := dijkstraUntil(y.done, y.src, spur, y.to, func(edge Edge) bool {
+		if _, ok := blocked[edge.End.key]; ok {
+			return false
+		}
+
+		_, ok := removed[[2]internal.Key{edge.Start.key, edge.End.key}]
+		return !ok
+	}, y.cost)
+	if !ok {
+		y.stopped = true
+		return internal.Path{}, false
+	}
+
+	if len(tail.Vertices) == 0 {
+		return internal.Path{}, false
+	}
+
+	path := internal.Path{
+		Vertices: make([]internal.Key, 0, len(root)+len(tail.Vertices)-1),
+	}
+
+	for j := 0; j < i; j++ {
+		edge, _ := y.src.cheapestEdge(root[j], root[j+1], y.cost)
+		path.Cost += y.cost(edge)
+	}
+
+	path.Cost += tail.Cost
+	path.Vertices = append(path.Vertices, root[:i]...)
+	path.Vertices = append(path.Vertices, tail.Vertices...)
+
+	return path, true
+}
+
+func hasPrefix(keys []internal.Key, prefix []internal.Key) bool {
+	if len(keys) < len(prefix) {
+		return false
+	}
+
+	for i := range prefix {
+		if keys[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func pathID(path internal.Path) string {
+	buf := make([]byte, 0, len(path.Vertices)*8)
+	for _, key := range path.Vertices {
+		for shift := uint(0); shift < 64; shift += 8 {
+			buf = append(buf, byte(key>>shift))
+		}
+	}
+
+	return string(buf)
+}
@@ -0,0 +1,251 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKShortest(t *testing.T) {
+	var (
+		g    = graph.New()
+		keyC = g.AddVertex('C')
+		keyD = g.AddVertex('D')
+		keyE = g.AddVertex('E')
+		keyF = g.AddVertex('F')
+		keyG = g.AddVertex('G')
+		keyH = g.AddVertex('H')
+	)
+
+	g.AddEdgeCost(keyC, keyD, 3)
+	g.AddEdgeCost(keyC, keyE, 2)
+	g.AddEdgeCost(keyD, keyF, 4)
+	g.AddEdgeCost(keyE, keyD, 1)
+	g.AddEdgeCost(keyE, keyF, 2)
+	g.AddEdgeCost(keyE, keyG, 3)
+	g.AddEdgeCost(keyF, keyG, 2)
+	g.AddEdgeCost(keyF, keyH, 1)
+	g.AddEdgeCost(keyG, keyH, 2)
+
+	paths := g.FindPaths(graph.KShortest(3), keyC, keyH)
+	require.Len(t, paths, 3)
+
+	require.Equal(t, graph.Path{
+		Cost:     5,
+		Vertices: []graph.Key{keyC, keyE, keyF, keyH},
+	}, paths[0])
+	require.Equal(t, graph.Path{
+		Cost:     7,
+		Vertices: []graph.Key{keyC, keyE, keyG, keyH},
+	}, paths[1])
+
+	// There are three paths of cost 8; any is acceptable.
+	require.Equal(t, 8, paths[2].Cost)
+	require.Contains(t, [][]graph.Key{
+		{keyC, keyD, keyF, keyH},
+		{keyC, keyE, keyD, keyF, keyH},
+		{keyC, keyE, keyF, keyG, keyH},
+	}, paths[2].Vertices)
+
+	// Asking for more paths than exist returns every loopless path.
+	paths = g.FindPaths(graph.KShortest(100), keyC, keyH)
+	require.Len(t, paths, 7)
+	require.True(t, sort.SliceIsSorted(paths, func(i int, j int) bool {
+		return paths[i].Cost < paths[j].Cost
+	}))
+}
+
+func TestKShortestNoPath(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	g.AddEdge(k2, k1)
+
+	require.Nil(t, g.FindPaths(graph.KShortest(3), k1, k2))
+	require.Nil(t, g.FindPaths(graph.KShortest(0), k2, k1))
+	require.Equal(
+		t,
+		graph.Paths{{Cost: 1, Vertices: []graph.Key{k2, k1}}},
+		g.FindPaths(graph.KShortest(3), k2, k1),
+	)
+}
+
+func TestKShortestContext(t *testing.T) {
+	g, keys := newDetourGraph()
+
+	paths, err := g.FindPathsContext(
+		context.Background(),
+		graph.KShortestContext(3),
+		keys[0],
+		keys[2],
+	)
+	require.NoError(t, err)
+	require.Equal(t, g.FindPaths(graph.KShortest(3), keys[0], keys[2]), paths)
+	require.Len(t, paths, 2)
+
+	// Count the edges costed while finding the first (cheapest) path, and
+	// cancel the search as soon as it costs another.
+	var calls, limit int
+	g.FindPath(graph.DijkstraBy(func(edge graph.Edge) int {
+		limit++
+		return edge.Cost
+	}), keys[0], keys[2])
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cost := cancelingCost(cancel, func(graph.Edge) bool {
+		calls++
+		return calls > limit
+	})
+	algo := graph.KShortestByContext(3, cost)
+
+	paths, err = g.FindPathsContext(ctx, algo, keys[0], keys[2])
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, graph.Paths{{
+		Cost:     2,
+		Vertices: []graph.Key{keys[0], keys[1], keys[2]},
+	}}, paths)
+
+	algo = graph.KShortestContext(3)
+	paths, err = g.FindPathsContext(ctx, algo, keys[0], keys[2])
+	require.Equal(t, context.Canceled, err)
+	require.Nil(t, paths)
+}
+
+func TestKShortestTies(t *testing.T) {
+	var (
+		g    = graph.New()
+		src  = g.AddVertex("src")
+		dst  = g.AddVertex("dst")
+		mids = make([]graph.Key, 6)
+	)
+
+	for i := range mids {
+		mids[i] = g.AddVertex(i)
+		g.AddEdge(src, mids[i])
+		g.AddEdge(mids[i], dst)
+	}
+
+	// Frozen graphs visit edges in key order, so paths of equal cost are
+	// discovered in key order, and must be returned that way.
+	paths := g.Freeze().KShortest(len(mids), src, dst)
+	require.Len(t, paths, len(mids))
+
+	for i, path := range paths {
+		require.Equal(t, 2, path.Cost)
+		require.Equal(t, []graph.Key{src, mids[i], dst}, path.Vertices)
+	}
+}
+
+func TestKShortestRandom(t *testing.T) {
+	var (
+		rng  = rand.New(rand.NewSource(3))
+		g    = graph.New()
+		keys = make([]graph.Key, 8)
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i)
+	}
+
+	for i := 0; i < 24; i++ {
+		g.AddEdgeCost(
+			keys[rng.Intn(len(keys))],
+			keys[rng.Intn(len(keys))],
+			1+rng.Intn(10),
+		)
+	}
+
+	for _, from := range keys {
+		for _, to := range keys {
+			var (
+				expected = simplePathCosts(g, from, to)
+				paths    = g.FindPaths(graph.KShortest(5), from, to)
+				actual   = make([]int, len(paths))
+				seen     = make(map[string]struct{})
+			)
+
+			for i, path := range paths {
+				require.Equal(t, path.Cost, pathCost(t, g, path))
+
+				id := fmt.Sprint(path.Vertices)
+				require.NotContains(t, seen, id)
+				seen[id] = struct{}{}
+
+				actual[i] = path.Cost
+			}
+
+			if len(expected) > 5 {
+				expected = expected[:5]
+			}
+
+			require.Equal(t, expected, actual)
+		}
+	}
+}
+
+// simplePathCosts exhaustively enumerates the loopless paths spanning from and
+// to, returning their sorted costs.
+func simplePathCosts(g *graph.Graph, from graph.Key, to graph.Key) []int {
+	var (
+		costs   = []int{}
+		visited = make(map[graph.Key]bool)
+		walk    func(graph.Key, int)
+	)
+
+	walk = func(key graph.Key, cost int) {
+		if key == to {
+			costs = append(costs, cost)
+			return
+		}
+
+		visited[key] = true
+		defer delete(visited, key)
+
+		var edges []graph.Edge
+		g.VisitEdges(key, func(edge graph.Edge) bool {
+			edges = append(edges, edge)
+			return true
+		})
+
+		for _, edge := range edges {
+			if !visited[edge.End.Key()] {
+				walk(edge.End.Key(), cost+edge.Cost)
+			}
+		}
+	}
+
+	walk(from, 0)
+	sort.Ints(costs)
+
+	return costs
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"sort"
+
+	"github.com/mway/pkg/x/container/unionfind"
+)
+
+// MinimumSpanningForest returns a new graph containing the same vertices (and
+// keys) as g, but only the edges of a minimum spanning forest of g: for each
+// connected component of g, the returned graph contains a spanning tree of
+// that component with the least possible total cost.
+//
+// Edge direction is ignored when selecting edges, but selected edges retain
+// their original direction and cost. Prim's algorithm is used for dense graphs
+// and Kruskal's algorithm for sparse graphs.
+func (g *Graph) MinimumSpanningForest() *Graph {
+	dup, _ := g.minimumSpanningForest()
+	return dup
+}
+
+// MinimumSpanningTree behaves like MinimumSpanningForest, except that if g is
+// not connected (and thus has no spanning tree), ErrDisconnected is returned.
+func (g *Graph) MinimumSpanningTree() (*Graph, error) {
+	dup, connected := g.minimumSpanningForest()
+	if !connected {
+		return nil, ErrDisconnected
+	}
+
+	return dup, nil
+}
+
+func (g *Graph) minimumSpanningForest() (*Graph, bool) {
+	g.mtx.RLock()
+	var (
+		snap = g.snapshotUnsafe()
+		dup  = g.cloneVerticesUnsafe()
+	)
+	g.mtx.RUnlock()
+
+	var edges []spanEdge
+	if snap.dense() {
+		edges = prim(snap)
+	} else {
+		edges = kruskal(snap)
+	}
+
+	for _, edge := range edges {
+		dup.putEdgeUnsafe(snap.keys[edge.from], snap.keys[edge.to], edge.edgeData)
+	}
+
+	// A spanning forest has exactly one fewer edge than vertices only when it
+	// is a single tree.
+	return dup, len(snap.keys) == 0 || len(edges) == len(snap.keys)-1
+}
+
+type spanEdge struct {
+	edgeData
+
+	from int
+	to   int
+}
+
+func kruskal(snap *snapshot) []spanEdge {
+	edges := make([]spanEdge, 0, snap.size)
+	for from, arcs := range snap.arcs {
+		for _, edge := range arcs {
+			if edge.to != from {
+				edges = append(edges, spanEdge{
+					edgeData: edge.edgeData,
+					from:     from,
+					to:       edge.to,
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(edges, func(i int, j int) bool {
+		return edges[i].cost < edges[j].cost
+	})
+
+	var (
+		sets unionfind.UnionFind
+		tree []spanEdge
+	)
+
+	for _, edge := range edges {
+		if sets.Union(edge.from, edge.to) {
+			tree = append(tree, edge)
+		}
+	}
+
+	return tree
+}
+
+func prim(snap *snapshot) []spanEdge {
+	var (
+		n     = len(snap.keys)
+		state = primState{
+			n:      n,
+			links:  make([]spanEdge, n*n),
+			linked: make([]bool, n*n),
+			done:   make([]bool, n),
+			via:    make([]int, n),
+		}
+		tree []spanEdge
+	)
+
+	// Collapse edges into an undirected adjacency matrix of the cheapest edge
+	// spanning each pair of vertices, retaining the edge's original direction.
+	for from, arcs := range snap.arcs {
+		for _, edge := range arcs {
+			state.link(spanEdge{
+				edgeData: edge.edgeData,
+				from:     from,
+				to:       edge.to,
+			})
+		}
+	}
+
+	for i := range state.via {
+		state.via[i] = -1
+	}
+
+	for i := 0; i < n; i++ {
+		u := state.next()
+		state.done[u] = true
+
+		if state.via[u] >= 0 {
+			tree = append(tree, state.links[state.via[u]])
+		}
+
+		for v := 0; v < n; v++ {
+			state.relax(u, v)
+		}
+	}
+
+	return tree
+}
+
+type primState struct {
+	n      int
+	links  []spanEdge
+	linked []bool
+	done   []bool
+	via    []int // index into links of the cheapest edge to each vertex, or -1
+}
+
+func (p *primState) link(edge spanEdge) {
+	if edge.from == edge.to {
+		return
+	}
+
+	for _, idx := range [2]int{
+		edge.from*p.n + edge.to,
+		edge.to*p.n + edge.from,
+	} {
+		if !p.linked[idx] || edge.cost < p.links[idx].cost {
+			p.links[idx] = edge
+			p.linked[idx] = true
+		}
+	}
+}
+
+// next returns the cheapest vertex to add to the forest. If no remaining
+// vertex is reachable from the current tree, the first remaining vertex is
+// returned, starting a new tree.
+func (p *primState) next() int {
+	u := -1
+
+	for v, done := range p.done {
+		switch {
+		case done:
+		case u < 0:
+			u = v
+		case p.via[v] < 0:
+		case p.via[u] < 0 || p.cost(v) < p.cost(u):
+			u = v
+		}
+	}
+
+	return u
+}
+
+func (p *primState) relax(u int, v int) {
+	idx := u*p.n + v
+	if p.done[v] || !p.linked[idx] {
+		return
+	}
+
+	if p.via[v] < 0 || p.links[idx].cost < p.cost(v) {
+		p.via[v] = idx
+	}
+}
+
+func (p *primState) cost(v int) int {
+	return p.links[p.via[v]].cost
+}
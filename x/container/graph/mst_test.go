@@ -0,0 +1,202 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMinimumSpanningTree(t *testing.T) {
+	var (
+		g    = graph.NewUndirected()
+		keyA = g.AddVertex('A')
+		keyB = g.AddVertex('B')
+		keyC = g.AddVertex('C')
+		keyD = g.AddVertex('D')
+		keyE = g.AddVertex('E')
+	)
+
+	g.AddEdgeCost(keyA, keyB, 1)
+	g.AddEdgeCost(keyA, keyC, 7)
+	g.AddEdgeCost(keyB, keyC, 5)
+	g.AddEdgeCost(keyB, keyD, 4)
+	g.AddEdgeCost(keyB, keyE, 3)
+	g.AddEdgeCost(keyC, keyE, 6)
+	g.AddEdgeCost(keyD, keyE, 2)
+
+	mst, err := g.MinimumSpanningTree()
+	require.NoError(t, err)
+	require.Equal(t, g.Order(), mst.Order())
+
+	var actual []string
+	mst.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		actual = append(actual, edge.String())
+		return true
+	})
+
+	require.ElementsMatch(t, []string{
+		(&graph.Edge{Start: get(t, g, keyA), End: get(t, g, keyB), Cost: 1}).String(),
+		(&graph.Edge{Start: get(t, g, keyB), End: get(t, g, keyC), Cost: 5}).String(),
+		(&graph.Edge{Start: get(t, g, keyB), End: get(t, g, keyE), Cost: 3}).String(),
+		(&graph.Edge{Start: get(t, g, keyD), End: get(t, g, keyE), Cost: 2}).String(),
+	}, actual)
+
+	// Disconnecting the graph leaves a forest but no tree.
+	keyF := g.AddVertex('F')
+
+	mst, err = g.MinimumSpanningTree()
+	require.Nil(t, mst)
+	require.Equal(t, graph.ErrDisconnected, err)
+
+	forest := g.MinimumSpanningForest()
+	require.Equal(t, [][]graph.Key{
+		{keyA, keyB, keyC, keyD, keyE},
+		{keyF},
+	}, forest.ConnectedComponents())
+}
+
+func TestMinimumSpanningForestRandom(t *testing.T) {
+	cases := []struct {
+		name  string
+		edges int
+	}{
+		{name: "sparse", edges: 30},
+		{name: "dense", edges: 300},
+	}
+
+	for i, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var (
+				rng  = rand.New(rand.NewSource(int64(i)))
+				g    = graph.New()
+				keys = make([]graph.Key, 24)
+			)
+
+			for i := range keys {
+				keys[i] = g.AddVertex(i)
+			}
+
+			for i := 0; i < tc.edges; i++ {
+				g.AddEdgeCost(
+					keys[rng.Intn(len(keys))],
+					keys[rng.Intn(len(keys))],
+					rng.Intn(20)-5,
+				)
+			}
+
+			forest := g.MinimumSpanningForest()
+			requireMinimumSpanningForest(t, g, forest)
+		})
+	}
+}
+
+func TestMinimumSpanningTreeEmpty(t *testing.T) {
+	mst, err := graph.New().MinimumSpanningTree()
+	require.NoError(t, err)
+	require.Equal(t, 0, mst.Order())
+}
+
+// requireMinimumSpanningForest checks that forest spans the same components as
+// g and satisfies the cycle property: no edge outside of the forest is cheaper
+// than the most expensive edge on the forest path it would short-circuit.
+func requireMinimumSpanningForest(
+	t *testing.T,
+	g *graph.Graph,
+	forest *graph.Graph,
+) {
+	comps := g.ConnectedComponents()
+	require.Equal(t, comps, forest.ConnectedComponents())
+
+	var (
+		adj   = make(map[graph.Key][]graph.Edge)
+		edges int
+	)
+
+	forest.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		var (
+			start = edge.Start.Key()
+			end   = edge.End.Key()
+		)
+
+		adj[start] = append(adj[start], edge)
+		adj[end] = append(adj[end], graph.Edge{
+			Start: edge.End,
+			End:   edge.Start,
+			Cost:  edge.Cost,
+		})
+		edges++
+
+		return true
+	})
+
+	require.Equal(t, g.Order()-len(comps), edges)
+
+	// maxCost returns the most expensive edge on the forest path spanning from
+	// and to.
+	var maxCost func(from, to, parent graph.Key, max int) (int, bool)
+	maxCost = func(from, to, parent graph.Key, max int) (int, bool) {
+		if from == to {
+			return max, true
+		}
+
+		for _, edge := range adj[from] {
+			next := edge.End.Key()
+			if next == parent {
+				continue
+			}
+
+			cur := max
+			if edge.Cost > cur {
+				cur = edge.Cost
+			}
+
+			if found, ok := maxCost(next, to, from, cur); ok {
+				return found, true
+			}
+		}
+
+		return 0, false
+	}
+
+	g.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		start, end := edge.Start.Key(), edge.End.Key()
+		if start == end {
+			return true
+		}
+
+		max, ok := maxCost(start, end, graph.Key{}, math.MinInt32)
+		require.True(t, ok)
+		require.LessOrEqual(t, max, edge.Cost)
+
+		return true
+	})
+}
+
+func get(t *testing.T, g *graph.Graph, key graph.Key) graph.Vertex {
+	vertex, ok := g.Get(key)
+	require.True(t, ok)
+	return vertex
+}
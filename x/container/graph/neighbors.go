@@ -0,0 +1,109 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// EdgeCost returns the cost of the edge spanning from and to, if such an edge
+// exists. If g is a multigraph, the cost of the cheapest parallel edge is
+// returned.
+func (g *Graph) EdgeCost(from Key, to Key) (int, bool) {
+	edge, ok := g.cheapestEdge(from.key, to.key, edgeCost)
+	return edge.Cost, ok
+}
+
+// HasEdge returns whether an edge spanning from and to exists.
+func (g *Graph) HasEdge(from Key, to Key) bool {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	_, ok := g.edges[from.key][to.key]
+	return ok
+}
+
+// InDegree returns the number of edges ending at the vertex key. Parallel
+// edges are counted separately. For undirected graphs, InDegree is equal to
+// OutDegree.
+func (g *Graph) InDegree(key Key) int {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return degree(g.redges[key.key])
+}
+
+// OutDegree returns the number of edges starting at the vertex key. Parallel
+// edges are counted separately.
+func (g *Graph) OutDegree(key Key) int {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return degree(g.edges[key.key])
+}
+
+// Predecessors returns the keys of the vertices with an edge ending at the
+// vertex key, in key order. Each vertex is returned once, regardless of how
+// many parallel edges span it and key.
+func (g *Graph) Predecessors(key Key) []Key {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return neighbors(g.redges[key.key])
+}
+
+// Successors returns the keys of the vertices with an edge starting at the
+// vertex key, in key order. Each vertex is returned once, regardless of how
+// many parallel edges span key and it.
+func (g *Graph) Successors(key Key) []Key {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return neighbors(g.edges[key.key])
+}
+
+func degree(edges map[internal.Key]edgeList) (n int) {
+	for _, list := range edges {
+		n += len(list)
+	}
+
+	return
+}
+
+func neighbors(edges map[internal.Key]edgeList) []Key {
+	if len(edges) == 0 {
+		return nil
+	}
+
+	keys := make([]internal.Key, 0, len(edges))
+	for key := range edges {
+		keys = append(keys, key)
+	}
+
+	sortKeys(keys)
+
+	dup := make([]Key, len(keys))
+	for i, key := range keys {
+		dup[i] = newKey(key)
+	}
+
+	return dup
+}
@@ -0,0 +1,175 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNeighbors(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+	)
+
+	g.AddEdgeCost(k1, k2, 2)
+	g.AddEdgeCost(k1, k3, 3)
+	g.AddEdgeCost(k3, k2, 4)
+	g.AddEdgeCost(k3, k3, 5)
+
+	require.Equal(t, 2, g.OutDegree(k1))
+	require.Equal(t, 0, g.InDegree(k1))
+	require.Equal(t, 2, g.InDegree(k2))
+	require.Equal(t, 2, g.OutDegree(k3))
+	require.Equal(t, 2, g.InDegree(k3))
+	require.Equal(t, 0, g.OutDegree(k4))
+	require.Equal(t, 0, g.InDegree(graph.Key{}))
+
+	require.Equal(t, []graph.Key{k2, k3}, g.Successors(k1))
+	require.Equal(t, []graph.Key{k1, k3}, g.Predecessors(k2))
+	require.Equal(t, []graph.Key{k2, k3}, g.Successors(k3))
+	require.Empty(t, g.Successors(k4))
+	require.Empty(t, g.Predecessors(k4))
+
+	require.True(t, g.HasEdge(k1, k2))
+	require.False(t, g.HasEdge(k2, k1))
+
+	cost, ok := g.EdgeCost(k3, k2)
+	require.True(t, ok)
+	require.Equal(t, 4, cost)
+
+	_, ok = g.EdgeCost(k2, k3)
+	require.False(t, ok)
+}
+
+func TestNeighborsUndirected(t *testing.T) {
+	var (
+		g  = graph.NewUndirected()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+	)
+
+	g.AddEdge(k1, k2)
+	g.AddEdge(k3, k1)
+
+	for _, key := range []graph.Key{k1, k2, k3} {
+		require.Equal(t, g.OutDegree(key), g.InDegree(key))
+		require.Equal(t, g.Successors(key), g.Predecessors(key))
+	}
+
+	require.Equal(t, 2, g.OutDegree(k1))
+	require.Equal(t, []graph.Key{k2, k3}, g.Successors(k1))
+	require.True(t, g.HasEdge(k2, k1))
+}
+
+func TestNeighborsMultigraph(t *testing.T) {
+	var (
+		g  = graph.NewMultigraph()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+	)
+
+	g.AddEdgeCost(k1, k2, 3)
+	g.AddEdgeCost(k1, k2, 1)
+	g.AddEdgeCost(k1, k2, 2)
+
+	require.Equal(t, 3, g.OutDegree(k1))
+	require.Equal(t, 3, g.InDegree(k2))
+	require.Equal(t, []graph.Key{k2}, g.Successors(k1))
+	require.Equal(t, []graph.Key{k1}, g.Predecessors(k2))
+
+	cost, ok := g.EdgeCost(k1, k2)
+	require.True(t, ok)
+	require.Equal(t, 1, cost)
+}
+
+func TestSize(t *testing.T) {
+	var (
+		directed   = graph.New()
+		undirected = graph.NewUndirected()
+		multigraph = graph.NewUndirectedMultigraph()
+	)
+
+	for _, g := range []*graph.Graph{directed, undirected, multigraph} {
+		var (
+			k1 = g.AddVertex(1)
+			k2 = g.AddVertex(2)
+			k3 = g.AddVertex(3)
+		)
+
+		require.Equal(t, 0, g.Size())
+
+		g.AddEdge(k1, k2)
+		g.AddEdge(k2, k1)
+		g.AddEdge(k2, k3)
+		g.AddEdge(k3, k3)
+	}
+
+	require.Equal(t, 4, directed.Size())
+	require.Equal(t, 3, undirected.Size())
+	require.Equal(t, 4, multigraph.Size())
+
+	for _, g := range []*graph.Graph{directed, undirected, multigraph} {
+		var (
+			size = g.Size()
+			dup  = g.FilterEdges(func(edge graph.Edge) bool {
+				return edge.Start.Key() != edge.End.Key()
+			})
+		)
+
+		require.Equal(t, size-1, dup.Size())
+		require.Equal(t, size, g.Size())
+
+		var edges int
+		dup.VisitEdges(graph.Root, func(graph.Edge) bool {
+			edges++
+			return true
+		})
+		require.Equal(t, edges, dup.Size())
+
+		dup.DeleteVertex(graph.Key{})
+		require.Equal(t, edges, dup.Size())
+		require.Equal(t, 0, dup.FilterVertices(func(graph.Vertex) bool {
+			return false
+		}).Size())
+	}
+
+	key, _ := multigraph.AddEdgeKey(
+		multigraph.AddVertex(4),
+		multigraph.AddVertex(5),
+		graph.EdgeAttrs{},
+	)
+	require.Equal(t, 5, multigraph.Size())
+
+	multigraph.DeleteEdgeKey(key)
+	multigraph.DeleteEdgeKey(key)
+	require.Equal(t, 4, multigraph.Size())
+
+	forest := multigraph.MinimumSpanningForest()
+	require.Equal(t, 2, forest.Size())
+}
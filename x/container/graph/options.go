@@ -0,0 +1,51 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+// An Option configures a Graph during construction.
+type Option func(*Graph)
+
+// VertexIndexFunc is used by Graph to derive an index key from a vertex value.
+// It is called while the graph is locked, and thus must not call any methods
+// of the graph; doing so deadlocks.
+type VertexIndexFunc = func(interface{}) string
+
+// WithValueCodec configures a graph to use codec to encode and decode vertex
+// values when it is serialized. By default, JSONValueCodec is used.
+func WithValueCodec(codec ValueCodec) Option {
+	return func(g *Graph) {
+		g.config.codec = codec
+	}
+}
+
+// WithVertexIndex configures a graph to index its vertices by the key that fn
+// returns for each vertex's value, allowing vertices to be found by Lookup in
+// constant time. Index keys should be unique; if several vertices share an
+// index key, Lookup returns whichever of them was most recently added or
+// updated, falling back to the next most recent if it is deleted or updated
+// away.
+//
+// Unlike other callbacks, fn is called with g locked (see VertexIndexFunc).
+func WithVertexIndex(fn VertexIndexFunc) Option {
+	return func(g *Graph) {
+		g.config.index = fn
+	}
+}
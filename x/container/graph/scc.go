@@ -0,0 +1,217 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"sort"
+)
+
+// StronglyConnectedComponents returns the strongly connected components of g,
+// computed with Tarjan's algorithm. Every vertex belongs to exactly one
+// component, and vertices in the same component are mutually reachable.
+//
+// Components are returned in topological order (i.e., no component has an
+// edge to a component that precedes it), and the keys within each component
+// are ordered by key.
+func (g *Graph) StronglyConnectedComponents() [][]Key {
+	var (
+		snap  = g.snapshot()
+		comps = tarjan(snap)
+		keys  = make([][]Key, len(comps))
+	)
+
+	for i, comp := range comps {
+		keys[i] = make([]Key, len(comp))
+		for j, idx := range comp {
+			keys[i][j] = newKey(snap.keys[idx])
+		}
+	}
+
+	return keys
+}
+
+// Condense returns the condensation of g: a new graph with one vertex per
+// strongly connected component of g, whose value is the component's []Key
+// (as returned by StronglyConnectedComponents). For every edge in g spanning
+// two different components, the condensation contains an edge spanning those
+// components; if several edges span the same pair of components, the cheapest
+// cost is used. The condensation of a directed graph is always acyclic.
+func (g *Graph) Condense() *Graph {
+	var (
+		snap  = g.snapshot()
+		comps = tarjan(snap)
+		dup   = New()
+		owner = make([]int, len(snap.keys))
+		keys  = make([]Key, len(comps))
+		cheap = make(map[[2]int]int)
+		pairs [][2]int
+	)
+
+	// Only the shape of g carries over: the condensation's vertices are
+	// []Key, which g's vertex index and value codec do not understand.
+	dup.config.undirected = !g.IsDirected()
+	dup.config.multigraph = g.IsMultigraph()
+
+	for i, comp := range comps {
+		members := make([]Key, len(comp))
+		for j, idx := range comp {
+			owner[idx] = i
+			members[j] = newKey(snap.keys[idx])
+		}
+
+		keys[i] = dup.AddVertex(members)
+	}
+
+	for from, arcs := range snap.arcs {
+		for _, edge := range arcs {
+			pair := [2]int{owner[from], owner[edge.to]}
+			if pair[0] == pair[1] {
+				continue
+			}
+
+			if cost, ok := cheap[pair]; !ok {
+				pairs = append(pairs, pair)
+			} else if cost <= edge.cost {
+				continue
+			}
+
+			cheap[pair] = edge.cost
+		}
+	}
+
+	for _, pair := range pairs {
+		dup.AddEdgeCost(keys[pair[0]], keys[pair[1]], cheap[pair])
+	}
+
+	return dup
+}
+
+// tarjan computes the strongly connected components of snap, returning them in
+// topological order with each component's vertex indices in ascending order.
+func tarjan(snap *snapshot) [][]int {
+	n := len(snap.keys)
+
+	t := &tarjanState{
+		snap:    snap,
+		index:   make([]int, n),
+		low:     make([]int, n),
+		onStack: make([]bool, n),
+	}
+
+	for i := range t.index {
+		t.index[i] = -1
+	}
+
+	for root := 0; root < n; root++ {
+		if t.index[root] < 0 {
+			t.visit(root)
+		}
+	}
+
+	// Tarjan's algorithm emits components in reverse topological order.
+	comps := t.comps
+	for i, j := 0, len(comps)-1; i < j; i, j = i+1, j-1 {
+		comps[i], comps[j] = comps[j], comps[i]
+	}
+
+	return comps
+}
+
+type tarjanState struct {
+	snap    *snapshot
+	index   []int
+	low     []int
+	onStack []bool
+	stack   []int
+	comps   [][]int
+	counter int
+}
+
+// tarjanFrame tracks a vertex and the position of the next arc to explore,
+// emulating the recursion of the textbook algorithm without risking stack
+// exhaustion on deep graphs.
+type tarjanFrame struct {
+	v   int
+	pos int
+}
+
+func (t *tarjanState) visit(root int) {
+	t.push(root)
+	frames := []tarjanFrame{{v: root}}
+
+	for len(frames) > 0 {
+		var (
+			top = &frames[len(frames)-1]
+			v   = top.v
+		)
+
+		if top.pos < len(t.snap.arcs[v]) {
+			w := t.snap.arcs[v][top.pos].to
+			top.pos++
+
+			if t.index[w] < 0 {
+				t.push(w)
+				frames = append(frames, tarjanFrame{v: w})
+			} else if t.onStack[w] && t.index[w] < t.low[v] {
+				t.low[v] = t.index[w]
+			}
+
+			continue
+		}
+
+		frames = frames[:len(frames)-1]
+		if len(frames) > 0 {
+			if parent := frames[len(frames)-1].v; t.low[v] < t.low[parent] {
+				t.low[parent] = t.low[v]
+			}
+		}
+
+		if t.low[v] == t.index[v] {
+			t.emit(v)
+		}
+	}
+}
+
+func (t *tarjanState) push(v int) {
+	t.index[v], t.low[v] = t.counter, t.counter
+	t.counter++
+	t.stack = append(t.stack, v)
+	t.onStack[v] = true
+}
+
+// emit pops the component rooted at v off of the stack.
+func (t *tarjanState) emit(v int) {
+	var comp []int
+
+	for {
+		w := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[w] = false
+		comp = append(comp, w)
+
+		if w == v {
+			break
+		}
+	}
+
+	sort.Ints(comp)
+	t.comps = append(t.comps, comp)
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+		k5 = g.AddVertex(5)
+		k6 = g.AddVertex(6)
+		k7 = g.AddVertex(7)
+	)
+
+	// {1, 2, 3} -> {4, 5} -> {6}, {7} isolated
+	g.AddEdgeCost(k1, k2, 1)
+	g.AddEdgeCost(k2, k3, 1)
+	g.AddEdgeCost(k3, k1, 1)
+	g.AddEdgeCost(k3, k4, 5)
+	g.AddEdgeCost(k2, k5, 3)
+	g.AddEdgeCost(k4, k5, 1)
+	g.AddEdgeCost(k5, k4, 1)
+	g.AddEdgeCost(k5, k6, 2)
+
+	comps := g.StronglyConnectedComponents()
+	require.Len(t, comps, 4)
+	require.Contains(t, comps, []graph.Key{k1, k2, k3})
+	require.Contains(t, comps, []graph.Key{k4, k5})
+	require.Contains(t, comps, []graph.Key{k6})
+	require.Contains(t, comps, []graph.Key{k7})
+	requireComponentOrder(t, g, comps)
+
+	condensed := g.Condense()
+	require.Equal(t, 4, condensed.Order())
+
+	keys, err := condensed.TopologicalSort()
+	require.NoError(t, err)
+
+	members := make(map[graph.Key][]graph.Key)
+	for _, key := range keys {
+		vertex, ok := condensed.Get(key)
+		require.True(t, ok)
+		members[key] = vertex.Value().([]graph.Key)
+	}
+
+	require.ElementsMatch(t, comps, values(members))
+
+	var edges []string
+	condensed.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		var (
+			start = members[edge.Start.Key()]
+			end   = members[edge.End.Key()]
+		)
+
+		edges = append(edges, fmt.Sprintf("%v->%v(%d)", start, end, edge.Cost))
+		return true
+	})
+
+	require.ElementsMatch(t, []string{
+		fmt.Sprintf("%v->%v(%d)", []graph.Key{k1, k2, k3}, []graph.Key{k4, k5}, 3),
+		fmt.Sprintf("%v->%v(%d)", []graph.Key{k4, k5}, []graph.Key{k6}, 2),
+	}, edges)
+}
+
+func TestCondenseIndexedGraph(t *testing.T) {
+	var (
+		g = graph.New(graph.WithVertexIndex(func(v interface{}) string {
+			return v.(string)
+		}))
+		ka = g.AddVertex("a")
+		kb = g.AddVertex("b")
+	)
+
+	g.AddEdge(ka, kb)
+	g.AddEdge(kb, ka)
+
+	var condensed *graph.Graph
+	require.NotPanics(t, func() {
+		condensed = g.Condense()
+	})
+	require.Equal(t, 1, condensed.Order())
+	require.Equal(t, 0, condensed.Size())
+
+	condensed.VisitVertices(graph.Root, func(v graph.Vertex) bool {
+		require.ElementsMatch(t, []graph.Key{ka, kb}, v.Value())
+		return true
+	})
+}
+
+func TestStronglyConnectedComponentsRandom(t *testing.T) {
+	var (
+		rng  = rand.New(rand.NewSource(5))
+		g    = graph.New()
+		keys = make([]graph.Key, 40)
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i)
+	}
+
+	for i := 0; i < 60; i++ {
+		g.AddEdge(keys[rng.Intn(len(keys))], keys[rng.Intn(len(keys))])
+	}
+
+	var (
+		comps = g.StronglyConnectedComponents()
+		owner = make(map[graph.Key]int)
+	)
+
+	for i, comp := range comps {
+		for _, key := range comp {
+			require.NotContains(t, owner, key)
+			owner[key] = i
+		}
+	}
+
+	require.Len(t, owner, len(keys))
+	requireComponentOrder(t, g, comps)
+
+	reachable := func(from graph.Key, to graph.Key) (found bool) {
+		g.VisitVertices(from, func(vertex graph.Vertex) bool {
+			found = vertex.Key() == to
+			return !found
+		})
+		return
+	}
+
+	for _, a := range keys {
+		for _, b := range keys {
+			require.Equal(
+				t,
+				reachable(a, b) && reachable(b, a),
+				owner[a] == owner[b],
+			)
+		}
+	}
+
+	_, err := g.Condense().TopologicalSort()
+	require.NoError(t, err)
+}
+
+// requireComponentOrder requires that no edge in g points from a component to
+// an earlier one.
+func requireComponentOrder(t *testing.T, g *graph.Graph, comps [][]graph.Key) {
+	owner := make(map[graph.Key]int)
+	for i, comp := range comps {
+		for _, key := range comp {
+			owner[key] = i
+		}
+	}
+
+	g.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		require.LessOrEqual(
+			t,
+			owner[edge.Start.Key()],
+			owner[edge.End.Key()],
+		)
+		return true
+	})
+}
+
+func values(m map[graph.Key][]graph.Key) (vals [][]graph.Key) {
+	for _, v := range m {
+		vals = append(vals, v)
+	}
+	return
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// A ValueCodec encodes and decodes vertex values when a graph is serialized.
+type ValueCodec interface {
+	EncodeValue(value interface{}) ([]byte, error)
+	DecodeValue(data []byte) (interface{}, error)
+}
+
+// JSONValueCodec is a ValueCodec that uses encoding/json, and is used by graphs
+// that are not configured with another codec. Values are decoded as they would
+// be into an interface{} by json.Unmarshal; for example, numbers are decoded
+// as float64s.
+var JSONValueCodec ValueCodec = jsonValueCodec{}
+
+type jsonValueCodec struct{}
+
+func (jsonValueCodec) EncodeValue(value interface{}) ([]byte, error) {
+	return json.Marshal(value)
+}
+
+func (jsonValueCodec) DecodeValue(data []byte) (interface{}, error) {
+	var value interface{}
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// A serialGraph is the format-independent serialized form of a graph. Vertex
+// values are encoded by the graph's codec.
+type serialGraph struct {
+	directed   bool
+	multigraph bool
+	vertices   []serialVertex
+	edges      []serialEdge
+}
+
+// A serialVertex is a serialized vertex. A nil value represents a vertex whose
+// value was not serialized, and is decoded as nil.
+type serialVertex struct {
+	key   internal.Key
+	value []byte
+}
+
+type serialEdge struct {
+	from internal.Key
+	to   internal.Key
+	data edgeData
+}
+
+func (g *Graph) codec() ValueCodec {
+	if g.config.codec == nil {
+		return JSONValueCodec
+	}
+
+	return g.config.codec
+}
+
+// serialize returns the serialized form of g. Vertices and edges are ordered
+// by key, and each edge of an undirected graph is included once.
+func (g *Graph) serialize() (*serialGraph, error) {
+	g.mtx.RLock()
+	var (
+		snap = g.snapshotUnsafe()
+		sg   = &serialGraph{
+			directed:   !g.config.undirected,
+			multigraph: g.config.multigraph,
+			vertices:   make([]serialVertex, len(snap.keys)),
+			edges:      make([]serialEdge, 0, g.size),
+		}
+	)
+	g.mtx.RUnlock()
+
+	codec := g.codec()
+
+	for i, vertex := range snap.vertices {
+		value, err := codec.EncodeValue(vertex.value)
+		if err != nil {
+			return nil, fmt.Errorf("vertex %v: %w", vertex.key, err)
+		}
+
+		sg.vertices[i] = serialVertex{key: vertex.key, value: value}
+	}
+
+	for from, arcs := range snap.arcs {
+		for _, edge := range arcs {
+			if sg.directed || edge.to >= from {
+				sg.edges = append(sg.edges, serialEdge{
+					from: snap.keys[from],
+					to:   snap.keys[edge.to],
+					data: edge.edgeData,
+				})
+			}
+		}
+	}
+
+	return sg, nil
+}
+
+// deserialize replaces the contents of g with those of sg, preserving keys. If
+// sg cannot be decoded, g is left unchanged.
+func (g *Graph) deserialize(sg *serialGraph) error {
+	if err := sg.validate(); err != nil {
+		return err
+	}
+
+	values, err := sg.decode(g.codec())
+	if err != nil {
+		return err
+	}
+
+	g.mtx.Lock()
+	defer g.unlockAndPublish()
+
+	g.resetUnsafe()
+	g.config.undirected = !sg.directed
+	g.config.multigraph = sg.multigraph
+
+	for i, vertex := range sg.vertices {
+		node := Vertex{
+			key:   vertex.key,
+			graph: g,
+			value: values[i],
+		}
+
+		g.vertices[vertex.key] = node
+		g.indexVertexUnsafe(node)
+
+		if key := uint64(vertex.key); key > g.lastKey {
+			g.lastKey = key
+		}
+	}
+
+	if sg.multigraph {
+		for _, edge := range sg.edges {
+			if key := uint64(edge.data.key); key > g.lastEdgeKey {
+				g.lastEdgeKey = key
+			}
+		}
+	}
+
+	for _, edge := range sg.edges {
+		g.putEdgeUnsafe(edge.from, edge.to, g.newSerialEdgeDataUnsafe(edge))
+	}
+
+	g.recordUnsafe(Event{Type: GraphReset})
+
+	return nil
+}
+
+// newSerialEdgeDataUnsafe returns the data of edge as it should be added to g,
+// assigning a key if g is a multigraph and edge has none.
+func (g *Graph) newSerialEdgeDataUnsafe(edge serialEdge) edgeData {
+	data := edge.data
+
+	switch {
+	case !g.config.multigraph:
+		data.key = 0
+	case data.key == 0:
+		g.lastEdgeKey++
+		data.key = internal.Key(g.lastEdgeKey)
+	}
+
+	return data
+}
+
+// validate checks that the vertex keys of sg are valid and unique, and that
+// its edges reference only those vertices. Multigraph edge keys, if present,
+// must also be unique.
+func (sg *serialGraph) validate() error {
+	keys := make(map[internal.Key]struct{}, len(sg.vertices))
+
+	for _, vertex := range sg.vertices {
+		if _, dup := keys[vertex.key]; dup || !validKey(vertex.key) {
+			return fmt.Errorf("%w: vertex %v", ErrInvalidKey, vertex.key)
+		}
+
+		keys[vertex.key] = struct{}{}
+	}
+
+	return sg.validateEdges(keys)
+}
+
+func (sg *serialGraph) validateEdges(keys map[internal.Key]struct{}) error {
+	edgeKeys := make(map[internal.Key]struct{})
+
+	for _, edge := range sg.edges {
+		_, ok1 := keys[edge.from]
+		_, ok2 := keys[edge.to]
+		if !ok1 || !ok2 {
+			return fmt.Errorf("%w: edge %v->%v", ErrInvalidKey, edge.from, edge.to)
+		}
+
+		if key := edge.data.key; sg.multigraph && key != 0 {
+			if _, dup := edgeKeys[key]; dup || !validKey(key) {
+				return fmt.Errorf("%w: edge %v", ErrInvalidKey, key)
+			}
+
+			edgeKeys[key] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// decode decodes the values of sg's vertices with codec.
+func (sg *serialGraph) decode(codec ValueCodec) ([]interface{}, error) {
+	values := make([]interface{}, len(sg.vertices))
+
+	for i, vertex := range sg.vertices {
+		if vertex.value == nil {
+			continue
+		}
+
+		value, err := codec.DecodeValue(vertex.value)
+		if err != nil {
+			return nil, fmt.Errorf("vertex %v: %w", vertex.key, err)
+		}
+
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// validKey returns whether key may be used by a vertex or edge, i.e. it is not
+// zero, Any, or Root.
+func validKey(key internal.Key) bool {
+	return key != 0 && key < Root.key
+}
@@ -0,0 +1,361 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// A GraphDiff describes the changes that transform one graph into another, as
+// computed by Diff. Vertices are ordered by key, and edges by the keys of their
+// start vertices, end vertices, and then by their own keys.
+type GraphDiff struct {
+	// AddedVertices contains the vertices of b that are not in a.
+	AddedVertices []Vertex
+	// RemovedVertices contains the vertices of a that are not in b.
+	RemovedVertices []Vertex
+	// AddedEdges contains the edges of b that are not in a.
+	AddedEdges []Edge
+	// RemovedEdges contains the edges of a that are not in b.
+	RemovedEdges []Edge
+	// CostChanges contains the edges that are in both a and b, but whose
+	// costs differ.
+	CostChanges []EdgeChange
+}
+
+// An EdgeChange describes an edge that differs between two graphs.
+type EdgeChange struct {
+	Before Edge
+	After  Edge
+}
+
+// IsEmpty reports whether d contains no changes.
+func (d *GraphDiff) IsEmpty() bool {
+	return len(d.AddedVertices) == 0 &&
+		len(d.RemovedVertices) == 0 &&
+		len(d.AddedEdges) == 0 &&
+		len(d.RemovedEdges) == 0 &&
+		len(d.CostChanges) == 0
+}
+
+// Union returns a new graph containing the vertices and edges of both a and b.
+// Where a vertex or an edge is in both a and b, its value or attributes are
+// taken from b.
+//
+// The set operations (Union, Intersect, Difference, and Diff) require that a
+// and b share a key space, such as when b was cloned, filtered, or decoded
+// from a: vertices with the same key are the same vertex, as are edges
+// spanning the same vertices or, for multigraphs, edges with the same EdgeKey.
+// Each graph is read atomically, but a and b are not read at the same time.
+// If a and b are not both directed or both undirected, or are not both
+// multigraphs or both not, ErrIncompatible is returned; if a and b are
+// multigraphs with an EdgeKey that spans different vertices in each (i.e. they
+// do not share a key space), ErrInvalidKey is returned. The graphs returned by
+// set operations retain the keys and configuration (e.g. vertex indexing) of a.
+func Union(a *Graph, b *Graph) (*Graph, error) {
+	as, bs, err := setOperands(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	out := as.result(bs)
+
+	vertices := as.vertices
+	for key, vertex := range bs.vertices {
+		vertices[key] = vertex
+	}
+
+	edges := as.edges
+	for id, edge := range bs.edges {
+		edges[id] = edge
+	}
+
+	return out.fillUnsafe(vertices, edges), nil
+}
+
+// Intersect returns a new graph containing the vertices and edges that are in
+// both a and b, with the values and attributes of those in a. See Union for
+// the requirements of set operations.
+func Intersect(a *Graph, b *Graph) (*Graph, error) {
+	as, bs, err := setOperands(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	out := as.result(bs)
+
+	for key := range as.vertices {
+		if _, ok := bs.vertices[key]; !ok {
+			delete(as.vertices, key)
+		}
+	}
+
+	for id := range as.edges {
+		if _, ok := bs.edges[id]; !ok {
+			delete(as.edges, id)
+		}
+	}
+
+	return out.fillUnsafe(as.vertices, as.edges), nil
+}
+
+// Difference returns a new graph containing every vertex of a, along with the
+// edges of a that are not in b. See Union for the requirements of set
+// operations.
+func Difference(a *Graph, b *Graph) (*Graph, error) {
+	as, bs, err := setOperands(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	out := as.result(bs)
+
+	for id := range as.edges {
+		if _, ok := bs.edges[id]; ok {
+			delete(as.edges, id)
+		}
+	}
+
+	return out.fillUnsafe(as.vertices, as.edges), nil
+}
+
+// Diff returns the changes that transform a into b. Edges are compared by cost
+// only; changes to other attributes are not reported. See Union for the
+// requirements of set operations.
+func Diff(a *Graph, b *Graph) (*GraphDiff, error) {
+	as, bs, err := setOperands(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &GraphDiff{}
+	diff.addVertices(as, bs)
+	diff.addEdges(as, bs)
+
+	return diff, nil
+}
+
+// addVertices records the vertices added and removed between as and bs.
+func (d *GraphDiff) addVertices(as *graphSet, bs *graphSet) {
+	for _, key := range sortedVertexKeys(as.vertices) {
+		if _, ok := bs.vertices[key]; !ok {
+			d.RemovedVertices = append(d.RemovedVertices, as.vertices[key])
+		}
+	}
+
+	for _, key := range sortedVertexKeys(bs.vertices) {
+		if _, ok := as.vertices[key]; !ok {
+			d.AddedVertices = append(d.AddedVertices, bs.vertices[key])
+		}
+	}
+}
+
+// addEdges records the edges added, removed, and changed between as and bs.
+func (d *GraphDiff) addEdges(as *graphSet, bs *graphSet) {
+	for _, id := range sortedEdgeIDs(as.edges) {
+		before := as.edges[id]
+
+		after, ok := bs.edges[id]
+		switch {
+		case !ok:
+			d.RemovedEdges = append(d.RemovedEdges, before)
+		case after.Cost != before.Cost:
+			d.CostChanges = append(d.CostChanges, EdgeChange{
+				Before: before,
+				After:  after,
+			})
+		}
+	}
+
+	for _, id := range sortedEdgeIDs(bs.edges) {
+		if _, ok := as.edges[id]; !ok {
+			d.AddedEdges = append(d.AddedEdges, bs.edges[id])
+		}
+	}
+}
+
+// An edgeID identifies an edge within a key space. The key is zero for edges
+// of graphs that are not multigraphs. Since each edge of an undirected graph
+// is visited once, from whichever end has the lower key, its ends are ordered
+// by key.
+type edgeID struct {
+	from internal.Key
+	to   internal.Key
+	key  internal.Key
+}
+
+// A graphSet is a copy of the vertices and edges of a graph, keyed for set
+// operations. empty is a graph with the same configuration and keys as the
+// copied graph, but no vertices or edges.
+type graphSet struct {
+	empty    *Graph
+	vertices map[internal.Key]Vertex
+	edges    map[edgeID]Edge
+}
+
+func setOperands(a *Graph, b *Graph) (*graphSet, *graphSet, error) {
+	// The graphs are locked in turn, rather than together, so that concurrent
+	// operations on the same graphs in the opposite order cannot deadlock.
+	as, bs := a.set(), b.set()
+
+	if as.empty.config.undirected != bs.empty.config.undirected ||
+		as.empty.config.multigraph != bs.empty.config.multigraph {
+		return nil, nil, ErrIncompatible
+	}
+
+	if err := as.checkEdgeKeys(bs); err != nil {
+		return nil, nil, err
+	}
+
+	return as, bs, nil
+}
+
+func (g *Graph) set() *graphSet {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	s := &graphSet{
+		empty:    &Graph{},
+		vertices: make(map[internal.Key]Vertex, len(g.vertices)),
+		edges:    make(map[edgeID]Edge, g.size),
+	}
+
+	s.empty.resetUnsafe()
+	s.empty.lastKey = g.lastKey
+	s.empty.lastEdgeKey = g.lastEdgeKey
+	s.empty.config = g.config
+
+	for key, vertex := range g.vertices {
+		s.vertices[key] = vertex
+	}
+
+	for _, edge := range g.allEdgesUnsafe() {
+		s.edges[newEdgeID(edge)] = edge
+	}
+
+	return s
+}
+
+// checkEdgeKeys returns an error if an EdgeKey of s identifies an edge that
+// spans different vertices in other.
+func (s *graphSet) checkEdgeKeys(other *graphSet) error {
+	if !s.empty.config.multigraph {
+		return nil
+	}
+
+	ids := make(map[internal.Key]edgeID, len(s.edges))
+	for id := range s.edges {
+		ids[id.key] = id
+	}
+
+	for id := range other.edges {
+		if prev, ok := ids[id.key]; ok && prev != id {
+			return fmt.Errorf("%w: edge %v", ErrInvalidKey, id.key)
+		}
+	}
+
+	return nil
+}
+
+// result returns an empty graph with the configuration of s, whose keys do not
+// collide with those of either s or other.
+func (s *graphSet) result(other *graphSet) *Graph {
+	out := s.empty
+	if other.empty.lastKey > out.lastKey {
+		out.lastKey = other.empty.lastKey
+	}
+
+	if other.empty.lastEdgeKey > out.lastEdgeKey {
+		out.lastEdgeKey = other.empty.lastEdgeKey
+	}
+
+	return out
+}
+
+// fillUnsafe adds vertices and edges to g, in key order, and returns g. Edges
+// that do not span two of vertices are skipped.
+func (g *Graph) fillUnsafe(
+	vertices map[internal.Key]Vertex,
+	edges map[edgeID]Edge,
+) *Graph {
+	for _, key := range sortedVertexKeys(vertices) {
+		g.vertices[key] = vertices[key]
+		g.indexVertexUnsafe(vertices[key])
+	}
+
+	for _, id := range sortedEdgeIDs(edges) {
+		_, fromOK := vertices[id.from]
+		_, toOK := vertices[id.to]
+		if !fromOK || !toOK {
+			continue
+		}
+
+		edge := edges[id]
+		g.putEdgeUnsafe(id.from, id.to, edgeData{
+			key:   edge.key,
+			cost:  edge.Cost,
+			extra: edge.extra,
+		})
+	}
+
+	return g
+}
+
+func newEdgeID(edge Edge) edgeID {
+	return edgeID{
+		from: edge.Start.key,
+		to:   edge.End.key,
+		key:  edge.key,
+	}
+}
+
+func sortedVertexKeys(vertices map[internal.Key]Vertex) []internal.Key {
+	keys := make([]internal.Key, 0, len(vertices))
+	for key := range vertices {
+		keys = append(keys, key)
+	}
+
+	sortKeys(keys)
+	return keys
+}
+
+func sortedEdgeIDs(edges map[edgeID]Edge) []edgeID {
+	ids := make([]edgeID, 0, len(edges))
+	for id := range edges {
+		ids = append(ids, id)
+	}
+
+	sort.Slice(ids, func(i int, j int) bool {
+		switch {
+		case ids[i].from != ids[j].from:
+			return ids[i].from < ids[j].from
+		case ids[i].to != ids[j].to:
+			return ids[i].to < ids[j].to
+		default:
+			return ids[i].key < ids[j].key
+		}
+	})
+
+	return ids
+}
@@ -0,0 +1,238 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+// newSetGraphs returns a graph a, and a graph b derived from a by removing
+// vertex 4, adding vertex 5 and an edge to it, and changing the cost of the
+// edge from 1 to 2.
+func newSetGraphs(t *testing.T) (*graph.Graph, *graph.Graph) {
+	a, keys := newChainGraph(4)
+	require.True(t, a.AddEdgeCost(keys[1], keys[2], 2))
+
+	b := a.FilterVertices(func(v graph.Vertex) bool {
+		return v.Key() != keys[3]
+	})
+
+	k5 := b.AddVertex(5)
+	require.True(t, b.AddEdgeCost(keys[0], k5, 7))
+	require.True(t, b.AddEdgeCost(keys[0], keys[1], 9))
+
+	return a, b
+}
+
+func TestUnion(t *testing.T) {
+	a, b := newSetGraphs(t)
+
+	g, err := graph.Union(a, b)
+	require.NoError(t, err)
+	require.Equal(t, 5, g.Order())
+	require.Equal(t, 4, g.Size())
+	require.ElementsMatch(
+		t,
+		[]string{"1->2(9)", "2->3(2)", "3->4(1)", "1->5(7)"},
+		edgeStrings(g, graph.Root),
+	)
+
+	// New vertices do not reuse keys of either graph.
+	k6 := g.AddVertex(6)
+	for _, src := range []*graph.Graph{a, b} {
+		_, ok := src.Get(k6)
+		require.False(t, ok)
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	a, b := newSetGraphs(t)
+
+	g, err := graph.Intersect(a, b)
+	require.NoError(t, err)
+	require.Equal(t, 3, g.Order())
+	require.Equal(t, 2, g.Size())
+	require.ElementsMatch(
+		t,
+		[]string{"1->2(1)", "2->3(2)"},
+		edgeStrings(g, graph.Root),
+	)
+}
+
+func TestDifference(t *testing.T) {
+	a, b := newSetGraphs(t)
+
+	g, err := graph.Difference(a, b)
+	require.NoError(t, err)
+	require.Equal(t, 4, g.Order())
+	require.Equal(t, []string{"3->4(1)"}, edgeStrings(g, graph.Root))
+
+	g, err = graph.Difference(b, a)
+	require.NoError(t, err)
+	require.Equal(t, 4, g.Order())
+	require.Equal(t, []string{"1->5(7)"}, edgeStrings(g, graph.Root))
+}
+
+func TestDiff(t *testing.T) {
+	a, b := newSetGraphs(t)
+
+	diff, err := graph.Diff(a, b)
+	require.NoError(t, err)
+	require.False(t, diff.IsEmpty())
+	require.Equal(t, []interface{}{5}, vertexValues(diff.AddedVertices))
+	require.Equal(t, []interface{}{4}, vertexValues(diff.RemovedVertices))
+	require.Equal(t, []string{"1->5(7)"}, formatEdges(diff.AddedEdges))
+	require.Equal(t, []string{"3->4(1)"}, formatEdges(diff.RemovedEdges))
+	require.Len(t, diff.CostChanges, 1)
+	require.Equal(t, 1, diff.CostChanges[0].Before.Cost)
+	require.Equal(t, 9, diff.CostChanges[0].After.Cost)
+
+	diff, err = graph.Diff(a, a.FilterEdges(func(graph.Edge) bool {
+		return true
+	}))
+	require.NoError(t, err)
+	require.True(t, diff.IsEmpty())
+}
+
+func TestDiffUndirectedMultigraph(t *testing.T) {
+	var (
+		a  = graph.NewUndirectedMultigraph()
+		k1 = a.AddVertex(1)
+		k2 = a.AddVertex(2)
+	)
+
+	e1, _ := a.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 1})
+	a.AddEdgeKey(k2, k1, graph.EdgeAttrs{Cost: 2})
+
+	b := a.FilterEdges(func(edge graph.Edge) bool {
+		return edge.Key() != e1
+	})
+	e3, _ := b.AddEdgeKey(k2, k1, graph.EdgeAttrs{Cost: 3})
+
+	diff, err := graph.Diff(a, b)
+	require.NoError(t, err)
+	require.Empty(t, diff.CostChanges)
+	require.Equal(t, []string{"1->2(1)"}, formatEdges(diff.RemovedEdges))
+	require.Equal(t, []string{"1->2(3)"}, formatEdges(diff.AddedEdges))
+	require.Equal(t, e3, diff.AddedEdges[0].Key())
+
+	g, err := graph.Union(a, b)
+	require.NoError(t, err)
+	require.True(t, g.IsMultigraph())
+	require.False(t, g.IsDirected())
+	require.Equal(t, 3, g.Size())
+
+	g, err = graph.Intersect(a, b)
+	require.NoError(t, err)
+	require.Equal(t, []string{"2->1(2)"}, edgeStrings(g, k2))
+
+	g.DeleteEdgeKey(e1)
+	require.Equal(t, 1, g.Size())
+}
+
+func TestUnionMultigraphEdgeKeys(t *testing.T) {
+	var (
+		a  = graph.NewMultigraph()
+		k1 = a.AddVertex(1)
+		k2 = a.AddVertex(2)
+		k3 = a.AddVertex(3)
+	)
+
+	a.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 1})
+
+	clone := func() *graph.Graph {
+		return a.FilterEdges(func(graph.Edge) bool {
+			return true
+		})
+	}
+
+	b := clone()
+	e2, _ := b.AddEdgeKey(k2, k3, graph.EdgeAttrs{Cost: 2})
+
+	g, err := graph.Union(a, b)
+	require.NoError(t, err)
+	require.Equal(t, 2, g.Size())
+
+	g.DeleteEdgeKey(e2)
+	require.Equal(t, []string{"1->2(1)"}, edgeStrings(g, graph.Root))
+
+	// Keys added to a and its clone independently collide.
+	c := clone()
+	e3, _ := a.AddEdgeKey(k1, k3, graph.EdgeAttrs{Cost: 3})
+	e4, _ := c.AddEdgeKey(k3, k1, graph.EdgeAttrs{Cost: 4})
+	require.Equal(t, e3, e4)
+
+	_, err = graph.Union(a, c)
+	require.True(t, errors.Is(err, graph.ErrInvalidKey), "%v", err)
+
+	_, err = graph.Diff(a, c)
+	require.True(t, errors.Is(err, graph.ErrInvalidKey), "%v", err)
+}
+
+func TestSetOperationsIncompatible(t *testing.T) {
+	pairs := [][2]*graph.Graph{
+		{graph.New(), graph.NewUndirected()},
+		{graph.New(), graph.NewMultigraph()},
+		{graph.NewUndirectedMultigraph(), graph.NewUndirected()},
+	}
+
+	for _, pair := range pairs {
+		_, err := graph.Union(pair[0], pair[1])
+		require.True(t, errors.Is(err, graph.ErrIncompatible))
+
+		_, err = graph.Intersect(pair[0], pair[1])
+		require.True(t, errors.Is(err, graph.ErrIncompatible))
+
+		_, err = graph.Difference(pair[1], pair[0])
+		require.True(t, errors.Is(err, graph.ErrIncompatible))
+
+		_, err = graph.Diff(pair[1], pair[0])
+		require.True(t, errors.Is(err, graph.ErrIncompatible))
+	}
+}
+
+func vertexValues(vertices []graph.Vertex) []interface{} {
+	values := make([]interface{}, len(vertices))
+	for i, vertex := range vertices {
+		values[i] = vertex.Value()
+	}
+
+	return values
+}
+
+func formatEdges(edges []graph.Edge) []string {
+	strs := make([]string, len(edges))
+	for i, edge := range edges {
+		strs[i] = fmt.Sprintf(
+			"%v->%v(%d)",
+			edge.Start.Value(),
+			edge.End.Value(),
+			edge.Cost,
+		)
+	}
+
+	return strs
+}
@@ -0,0 +1,145 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"math/bits"
+	"sort"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// An arc is an edge in a snapshot, referencing its end vertex by index.
+type arc struct {
+	edgeData
+
+	to int
+}
+
+// A snapshot is a point-in-time, index-based copy of a graph's vertices and
+// edges, used by algorithms that would otherwise repeatedly lock the graph.
+type snapshot struct {
+	keys     []internal.Key
+	vertices []Vertex
+	index    map[internal.Key]int
+	arcs     [][]arc
+	size     int
+}
+
+func (g *Graph) snapshot() *snapshot {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return g.snapshotUnsafe()
+}
+
+func (g *Graph) snapshotUnsafe() *snapshot {
+	snap := &snapshot{
+		keys:     make([]internal.Key, 0, len(g.vertices)),
+		vertices: make([]Vertex, len(g.vertices)),
+		index:    make(map[internal.Key]int, len(g.vertices)),
+		arcs:     make([][]arc, len(g.vertices)),
+	}
+
+	for key := range g.vertices {
+		snap.keys = append(snap.keys, key)
+	}
+
+	sortKeys(snap.keys)
+
+	for i, key := range snap.keys {
+		snap.index[key] = i
+		snap.vertices[i] = g.vertices[key]
+	}
+
+	for i, key := range snap.keys {
+		for end, list := range g.edges[key] {
+			for _, data := range list {
+				snap.arcs[i] = append(snap.arcs[i], arc{
+					edgeData: data,
+					to:       snap.index[end],
+				})
+			}
+		}
+
+		// Parallel edges remain in the order they were added.
+		sort.SliceStable(snap.arcs[i], func(x int, y int) bool {
+			return snap.arcs[i][x].to < snap.arcs[i][y].to
+		})
+
+		snap.size += len(snap.arcs[i])
+	}
+
+	return snap
+}
+
+// dense reports whether a Floyd-Warshall search, which costs O(V^3), is
+// expected to outperform V Dijkstra searches, which cost O(V*E*log(V)).
+func (s *snapshot) dense() bool {
+	n := len(s.keys)
+	return s.size*bits.Len(uint(n)) >= n*n
+}
+
+// potentials uses Bellman-Ford from a virtual source connected to every vertex
+// to compute vertex potentials that make every edge cost non-negative. If s has
+// a negative cycle, a *CycleError is returned.
+func (s *snapshot) potentials() ([]int, error) {
+	var (
+		order = len(s.keys) + 1 // including the virtual source
+		edges = make([]costedEdge, 0, s.size)
+		dist  = make(map[internal.Key]int, len(s.keys))
+		prev  = make(map[internal.Key]costedEdge)
+	)
+
+	// The virtual source's edges cost nothing, so relaxing them leaves every
+	// vertex with a distance of 0.
+	for i, key := range s.keys {
+		dist[key] = 0
+
+		for _, edge := range s.arcs[i] {
+			edges = append(edges, costedEdge{
+				from: key,
+				to:   s.keys[edge.to],
+				cost: edge.cost,
+			})
+		}
+	}
+
+	for i := 1; i < order; i++ {
+		if _, changed := relaxEdges(edges, dist, prev); !changed {
+			break
+		}
+	}
+
+	if key, changed := relaxEdges(edges, dist, prev); changed {
+		return nil, &CycleError{
+			Err:   ErrNegativeCycle,
+			Cycle: negativeCycle(order, prev, key),
+		}
+	}
+
+	potential := make([]int, len(s.keys))
+	for i, key := range s.keys {
+		potential[i] = dist[key]
+	}
+
+	return potential, nil
+}
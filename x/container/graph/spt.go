@@ -0,0 +1,231 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"context"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// A TreeOption configures ShortestPathTree.
+type TreeOption func(*treeConfig)
+
+type treeConfig struct {
+	cost    EdgeCostFunc
+	maxCost int
+	bounded bool
+}
+
+// WithTreeEdgeCost uses cost to determine the cost of each edge rather than
+// Edge.Cost. Costs must not be negative.
+func WithTreeEdgeCost(cost EdgeCostFunc) TreeOption {
+	return func(cfg *treeConfig) {
+		cfg.cost = cost
+	}
+}
+
+// WithTreeMaxCost limits a tree to the vertices whose cheapest path from the
+// source costs at most max. Since vertices beyond max are never explored, this
+// makes queries such as "every vertex within max of the source" cheaper than
+// computing a full tree.
+func WithTreeMaxCost(max int) TreeOption {
+	return func(cfg *treeConfig) {
+		cfg.maxCost = max
+		cfg.bounded = true
+	}
+}
+
+// A PathTree holds the cheapest paths from a single source vertex to every
+// vertex reachable from it, as computed by ShortestPathTree. It is immutable
+// and safe for concurrent use.
+type PathTree struct {
+	source internal.Key
+	dist   map[internal.Key]int
+	prev   map[internal.Key]internal.Key
+	order  []internal.Key
+}
+
+// ShortestPathTree computes the cheapest paths from the vertex from to every
+// vertex reachable from it, using a single run of Dijkstra's algorithm. Edge
+// costs must not be negative. If from does not exist, the returned tree is
+// empty.
+func ShortestPathTree(g *Graph, from Key, opts ...TreeOption) *PathTree {
+	tree, _ := shortestPathTree(nil, g, from.key, opts)
+	return tree
+}
+
+// ShortestPathTreeContext behaves identically to ShortestPathTree, but stops
+// once ctx is done. If it is stopped, ctx.Err() is returned along with a
+// partial tree, which holds the cheapest paths to the vertices that are
+// closest to the source.
+func ShortestPathTreeContext(
+	ctx context.Context,
+	g *Graph,
+	from Key,
+	opts ...TreeOption,
+) (*PathTree, error) {
+	tree, ok := shortestPathTree(ctx.Done(), g, from.key, opts)
+	if !ok {
+		return tree, ctx.Err()
+	}
+
+	return tree, nil
+}
+
+// DistanceTo returns the cost of the cheapest path from the source of t to key,
+// and whether key is reachable.
+func (t *PathTree) DistanceTo(key Key) (int, bool) {
+	dist, ok := t.dist[key.key]
+	return dist, ok
+}
+
+// PathTo returns the cheapest path from the source of t to key. If key is not
+// reachable, an empty path is returned.
+func (t *PathTree) PathTo(key Key) Path {
+	dist, ok := t.dist[key.key]
+	if !ok {
+		return Path{}
+	}
+
+	path := internal.Path{
+		Cost:     dist,
+		Vertices: []internal.Key{key.key},
+	}
+
+	for cur := key.key; cur != t.source; {
+		cur = t.prev[cur]
+		path.Vertices = append(path.Vertices, cur)
+	}
+
+	reverseKeys(path.Vertices)
+
+	return newPathFromInternal(path)
+}
+
+// Reachable returns the keys of the vertices reachable from the source of t,
+// including the source itself, ordered by the cost of their cheapest paths.
+// Vertices with paths of equal cost are ordered by key.
+func (t *PathTree) Reachable() []Key {
+	keys := make([]Key, len(t.order))
+	for i, key := range t.order {
+		keys[i] = newKey(key)
+	}
+
+	return keys
+}
+
+// Source returns the key of the source vertex of t.
+func (t *PathTree) Source() Key {
+	return newKey(t.source)
+}
+
+// shortestPathTree computes a shortest path tree from the vertex from, stopping
+// once done is closed. If it is stopped, shortestPathTree returns false along
+// with the vertices settled so far.
+func shortestPathTree(
+	done <-chan struct{},
+	src edgeSource,
+	from internal.Key,
+	opts []TreeOption,
+) (*PathTree, bool) {
+	cfg := treeConfig{
+		cost: edgeCost,
+	}
+
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	tree := &PathTree{
+		source: from,
+		dist:   make(map[internal.Key]int),
+		prev:   make(map[internal.Key]internal.Key),
+	}
+
+	if _, ok := src.vertex(from); !ok {
+		return tree, true
+	}
+
+	search := treeSearch{
+		cfg:   cfg,
+		tree:  tree,
+		best:  map[internal.Key]int{from: 0},
+		queue: internal.NewCostHeap(internal.Costed{Key: from}),
+	}
+
+	return tree, search.run(done, src)
+}
+
+// treeSearch is the state of a single shortest path tree computation. best
+// holds the cheapest known cost of each vertex that has been reached, but not
+// necessarily settled.
+type treeSearch struct {
+	cfg   treeConfig
+	tree  *PathTree
+	best  map[internal.Key]int
+	queue *internal.CostHeap
+}
+
+func (s *treeSearch) run(done <-chan struct{}, src edgeSource) bool {
+	for s.queue.Len() > 0 {
+		if isDone(done) {
+			return false
+		}
+
+		cur := s.queue.Pop()
+		if _, settled := s.tree.dist[cur.Key]; settled {
+			continue
+		}
+
+		s.tree.dist[cur.Key] = cur.Cost
+		s.tree.order = append(s.tree.order, cur.Key)
+
+		src.visitEdges(cur.Key, func(edge Edge) bool {
+			s.relax(cur, edge)
+			return true
+		})
+	}
+
+	return true
+}
+
+// relax records edge as the last step of the cheapest known path to its end
+// vertex, if it is.
+func (s *treeSearch) relax(cur internal.Costed, edge Edge) {
+	end := edge.End.key
+	if _, settled := s.tree.dist[end]; settled {
+		return
+	}
+
+	total := cur.Cost + s.cfg.cost(edge)
+	if s.cfg.bounded && total > s.cfg.maxCost {
+		return
+	}
+
+	if best, seen := s.best[end]; seen && best <= total {
+		return
+	}
+
+	s.best[end] = total
+	s.tree.prev[end] = cur.Key
+	s.queue.Push(internal.Costed{Key: end, Cost: total})
+}
@@ -0,0 +1,168 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"context"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShortestPathTree(t *testing.T) {
+	g, keys := newDetourGraph()
+	keyD := g.AddVertex('D')
+
+	tree := graph.ShortestPathTree(g, keys[0])
+	require.Equal(t, keys[0], tree.Source())
+	require.Equal(t, keys[:], tree.Reachable())
+
+	for i, cost := range []int{0, 1, 2} {
+		dist, ok := tree.DistanceTo(keys[i])
+		require.True(t, ok)
+		require.Equal(t, cost, dist)
+		require.Equal(
+			t,
+			g.FindPath(graph.Dijkstra, keys[0], keys[i]),
+			tree.PathTo(keys[i]),
+		)
+	}
+
+	_, ok := tree.DistanceTo(keyD)
+	require.False(t, ok)
+	require.Equal(t, graph.Path{}, tree.PathTo(keyD))
+
+	tree = graph.ShortestPathTree(g, keys[2])
+	require.Equal(t, []graph.Key{keys[2]}, tree.Reachable())
+	require.Equal(
+		t,
+		graph.Path{Vertices: []graph.Key{keys[2]}},
+		tree.PathTo(keys[2]),
+	)
+
+	tree = graph.ShortestPathTree(g, graph.Key{})
+	require.Empty(t, tree.Reachable())
+}
+
+func TestShortestPathTreeOptions(t *testing.T) {
+	g, keys := newDetourGraph()
+
+	tree := graph.ShortestPathTree(g, keys[0], graph.WithTreeMaxCost(1))
+	require.Equal(t, []graph.Key{keys[0], keys[1]}, tree.Reachable())
+
+	_, ok := tree.DistanceTo(keys[2])
+	require.False(t, ok)
+
+	// With every edge costing the same, the direct edge is cheapest.
+	tree = graph.ShortestPathTree(
+		g,
+		keys[0],
+		graph.WithTreeEdgeCost(func(graph.Edge) int { return 5 }),
+		graph.WithTreeMaxCost(5),
+	)
+	require.Equal(t, []graph.Key{keys[0], keys[1], keys[2]}, tree.Reachable())
+	require.Equal(t, graph.Path{
+		Cost:     5,
+		Vertices: []graph.Key{keys[0], keys[2]},
+	}, tree.PathTo(keys[2]))
+}
+
+func TestShortestPathTreeContext(t *testing.T) {
+	g, keys := newDetourGraph()
+
+	tree, err := graph.ShortestPathTreeContext(context.Background(), g, keys[0])
+	require.NoError(t, err)
+	require.Equal(t, graph.ShortestPathTree(g, keys[0]), tree)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel once the edges of B are explored; B itself has been settled.
+	cost := cancelingCost(cancel, func(e graph.Edge) bool {
+		return e.Start.Key() == keys[1]
+	})
+
+	tree, err = graph.ShortestPathTreeContext(
+		ctx,
+		g,
+		keys[0],
+		graph.WithTreeEdgeCost(cost),
+	)
+	require.Equal(t, context.Canceled, err)
+	require.Equal(t, []graph.Key{keys[0], keys[1]}, tree.Reachable())
+	require.Equal(t, graph.Path{}, tree.PathTo(keys[2]))
+
+	tree, err = graph.ShortestPathTreeContext(ctx, g, keys[0])
+	require.Equal(t, context.Canceled, err)
+	require.Empty(t, tree.Reachable())
+}
+
+func TestShortestPathTreeRandom(t *testing.T) {
+	var (
+		rng  = rand.New(rand.NewSource(7))
+		g    = graph.New()
+		keys = make([]graph.Key, 32)
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i)
+	}
+
+	for i := 0; i < 96; i++ {
+		g.AddEdgeCost(
+			keys[rng.Intn(len(keys))],
+			keys[rng.Intn(len(keys))],
+			rng.Intn(10),
+		)
+	}
+
+	var (
+		tree   = graph.ShortestPathTree(g, keys[0])
+		frozen = g.Freeze().ShortestPathTree(keys[0])
+		costs  []int
+	)
+
+	require.Equal(t, tree, frozen)
+
+	for _, key := range tree.Reachable() {
+		dist, ok := tree.DistanceTo(key)
+		require.True(t, ok)
+		costs = append(costs, dist)
+	}
+
+	require.True(t, sort.IntsAreSorted(costs))
+
+	for _, key := range keys {
+		var (
+			expected = g.FindPath(graph.Dijkstra, keys[0], key)
+			actual   = tree.PathTo(key)
+		)
+
+		require.Equal(t, expected.Cost, actual.Cost)
+		require.Equal(t, len(expected.Vertices) > 0, len(actual.Vertices) > 0)
+		if len(actual.Vertices) > 0 {
+			require.Equal(t, expected.Cost, pathCost(t, g, actual))
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// Induced returns the subgraph of g induced by keys: a new graph containing the
+// vertices of g represented by keys, along with every edge of g spanning two
+// such vertices. Keys that do not exist in g are ignored.
+//
+// Unlike FilterVertices, Induced only copies the requested vertices and their
+// edges. Vertices and edges retain their keys, so they may be correlated with
+// those of g.
+func (g *Graph) Induced(keys ...Key) *Graph {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	include := make(map[internal.Key]struct{}, len(keys))
+	for _, key := range keys {
+		if _, ok := g.vertices[key.key]; ok {
+			include[key.key] = struct{}{}
+		}
+	}
+
+	return g.subgraphUnsafe(include)
+}
+
+// Reachable returns the subgraph of g induced by the vertices reachable from
+// the vertex from by following at most maxDepth edges in the given direction,
+// including from itself; if maxDepth is negative, depth is not limited. For
+// example, Reachable(key, -1, Incoming) returns the subgraph of every vertex
+// from which key is reachable.
+//
+// The returned graph includes every edge of g spanning two of its vertices,
+// not only the edges that were followed. If from does not exist, the returned
+// graph is empty. As with Induced, keys are retained.
+func (g *Graph) Reachable(from Key, maxDepth int, direction Direction) *Graph {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	include := make(map[internal.Key]struct{})
+	if _, ok := g.vertices[from.key]; !ok {
+		return g.subgraphUnsafe(include)
+	}
+
+	edges := g.edges
+	if direction == Incoming {
+		edges = g.redges
+	}
+
+	include[from.key] = struct{}{}
+	frontier := []internal.Key{from.key}
+
+	for depth := 0; len(frontier) > 0 && depth != maxDepth; depth++ {
+		var next []internal.Key
+
+		for _, key := range frontier {
+			for end := range edges[key] {
+				if _, ok := include[end]; !ok {
+					include[end] = struct{}{}
+					next = append(next, end)
+				}
+			}
+		}
+
+		frontier = next
+	}
+
+	return g.subgraphUnsafe(include)
+}
+
+// subgraphUnsafe returns the subgraph of g induced by include, each of which
+// must be the key of a vertex of g.
+func (g *Graph) subgraphUnsafe(include map[internal.Key]struct{}) *Graph {
+	sub := &Graph{}
+	sub.resetUnsafe()
+	sub.lastKey = g.lastKey
+	sub.lastEdgeKey = g.lastEdgeKey
+	sub.config = g.config
+
+	for key := range include {
+		sub.vertices[key] = g.vertices[key]
+		g.copyEdgesUnsafe(sub, key, include)
+	}
+
+	// Index entries are copied, rather than recomputed, so that vertices
+	// sharing an index resolve as they do in g.
+	for index, owners := range g.index {
+		if owners = owners.within(include); len(owners) > 0 {
+			sub.index[index] = owners
+		}
+	}
+
+	return sub
+}
+
+// copyEdgesUnsafe copies the edges of g starting at the vertex start and ending
+// at any vertex in include into sub.
+func (g *Graph) copyEdgesUnsafe(
+	sub *Graph,
+	start internal.Key,
+	include map[internal.Key]struct{},
+) {
+	for end, list := range g.edges[start] {
+		if _, ok := include[end]; !ok {
+			continue
+		}
+
+		// Edge lists are immutable, and may be shared.
+		sub.getEdgesUnsafe(start)[end] = list
+		sub.getReverseEdgesUnsafe(end)[start] = list
+
+		// Each edge of an undirected graph is held in both directions, but
+		// counted (and keyed) once.
+		if g.config.undirected && end < start {
+			continue
+		}
+
+		sub.size += len(list)
+		for _, data := range list {
+			if g.config.multigraph {
+				sub.edgeEnds[data.key] = g.edgeEnds[data.key]
+			}
+		}
+	}
+}
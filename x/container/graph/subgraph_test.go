@@ -0,0 +1,170 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+// newChainGraph returns a directed graph of n vertices, with values 1 through
+// n, in which each vertex has an edge to the next, along with its keys.
+func newChainGraph(n int, opts ...graph.Option) (*graph.Graph, []graph.Key) {
+	var (
+		g    = graph.New(opts...)
+		keys = make([]graph.Key, n)
+	)
+
+	for i := range keys {
+		keys[i] = g.AddVertex(i + 1)
+		if i > 0 {
+			g.AddEdge(keys[i-1], keys[i])
+		}
+	}
+
+	return g, keys
+}
+
+func TestGraphInduced(t *testing.T) {
+	g, keys := newChainGraph(4, graph.WithVertexIndex(func(v interface{}) string {
+		return fmt.Sprint(v)
+	}))
+	g.AddEdgeCost(keys[0], keys[2], 5)
+
+	sub := g.Induced(keys[0], keys[2], keys[3], graph.Key{})
+	require.Equal(t, 3, sub.Order())
+	require.Equal(t, 2, sub.Size())
+	require.True(t, sub.IsDirected())
+	require.ElementsMatch(
+		t,
+		[]string{"1->3(5)", "3->4(1)"},
+		edgeStrings(sub, graph.Root),
+	)
+
+	for _, key := range []graph.Key{keys[0], keys[2], keys[3]} {
+		expected, ok := g.Get(key)
+		require.True(t, ok)
+
+		actual, ok := sub.Get(key)
+		require.True(t, ok)
+		require.Equal(t, expected.Value(), actual.Value())
+	}
+
+	_, ok := sub.Get(keys[1])
+	require.False(t, ok)
+
+	key, ok := sub.Lookup("3")
+	require.True(t, ok)
+	require.Equal(t, keys[2], key)
+
+	_, ok = sub.Lookup("2")
+	require.False(t, ok)
+
+	// New vertices do not reuse keys of g.
+	require.NotContains(t, keys, sub.AddVertex(5))
+
+	// The subgraph is independent of g.
+	sub.DeleteVertex(keys[0])
+	require.Equal(t, 4, g.Order())
+	require.Equal(t, 4, g.Size())
+	require.Zero(t, g.Induced().Order())
+}
+
+func TestGraphInducedUndirectedMultigraph(t *testing.T) {
+	var (
+		g  = graph.NewUndirectedMultigraph()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+	)
+
+	e1, _ := g.AddEdgeKey(k1, k2, graph.EdgeAttrs{Cost: 1})
+	g.AddEdgeKey(k2, k1, graph.EdgeAttrs{Cost: 2})
+	g.AddEdgeKey(k2, k2, graph.EdgeAttrs{Cost: 3})
+	g.AddEdgeKey(k2, k3, graph.EdgeAttrs{Cost: 4})
+
+	sub := g.Induced(k1, k2)
+	require.True(t, sub.IsMultigraph())
+	require.False(t, sub.IsDirected())
+	require.Equal(t, 3, sub.Size())
+	require.ElementsMatch(
+		t,
+		[]string{"1->2(1)", "1->2(2)", "2->2(3)"},
+		edgeStrings(sub, graph.Root),
+	)
+
+	sub.DeleteEdgeKey(e1)
+	require.Equal(t, 2, sub.Size())
+	require.Equal(t, 4, g.Size())
+	require.ElementsMatch(
+		t,
+		[]string{"2->1(2)", "2->2(3)"},
+		edgeStrings(sub, k2),
+	)
+}
+
+func TestGraphReachable(t *testing.T) {
+	g, keys := newChainGraph(5)
+	g.AddEdge(keys[3], keys[1])
+
+	cases := []struct {
+		from      graph.Key
+		depth     int
+		direction graph.Direction
+		expected  []string
+	}{
+		{keys[0], 0, graph.Outgoing, nil},
+		{keys[0], 1, graph.Outgoing, []string{"1->2(1)"}},
+		{keys[0], 2, graph.Outgoing, []string{"1->2(1)", "2->3(1)"}},
+		{
+			from:      keys[1],
+			depth:     -1,
+			direction: graph.Outgoing,
+			expected:  []string{"2->3(1)", "3->4(1)", "4->2(1)", "4->5(1)"},
+		},
+		{
+			from:      keys[2],
+			depth:     -1,
+			direction: graph.Incoming,
+			expected:  []string{"1->2(1)", "2->3(1)", "3->4(1)", "4->2(1)"},
+		},
+		{keys[4], 1, graph.Incoming, []string{"4->5(1)"}},
+		{graph.Key{}, -1, graph.Outgoing, nil},
+	}
+
+	for _, tt := range cases {
+		name := fmt.Sprintf("%v %d %d", tt.from, tt.depth, tt.direction)
+		t.Run(name, func(t *testing.T) {
+			sub := g.Reachable(tt.from, tt.depth, tt.direction)
+			require.ElementsMatch(t, tt.expected, edgeStrings(sub, graph.Root))
+
+			if _, ok := g.Get(tt.from); ok {
+				_, ok = sub.Get(tt.from)
+				require.True(t, ok)
+			} else {
+				require.Zero(t, sub.Order())
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"sort"
+
+	"github.com/mway/pkg/x/container/graph/internal"
+)
+
+// TopologicalSort returns the keys of g ordered such that for every edge, the
+// edge's start vertex precedes its end vertex. Vertices that are not otherwise
+// ordered relative to one another are ordered by key, so the result is
+// deterministic.
+//
+// If g is not a directed acyclic graph, a *CycleError wrapping ErrCycle is
+// returned, carrying one of the cycles in g.
+func (g *Graph) TopologicalSort() ([]Key, error) {
+	layers, err := g.TopologicalLayers()
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []Key
+	for _, layer := range layers {
+		keys = append(keys, layer...)
+	}
+
+	return keys, nil
+}
+
+// TopologicalLayers behaves like TopologicalSort, but groups keys into layers:
+// every vertex in a layer depends only on vertices in earlier layers, so the
+// vertices within a single layer may be processed in parallel. Keys within a
+// layer are ordered by key.
+func (g *Graph) TopologicalLayers() ([][]Key, error) {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	var (
+		degrees = make(map[internal.Key]int, len(g.vertices))
+		ready   []internal.Key
+		layers  [][]Key
+	)
+
+	for key := range g.vertices {
+		if degrees[key] = len(g.redges[key]); degrees[key] == 0 {
+			ready = append(ready, key)
+		}
+	}
+
+	for len(ready) > 0 {
+		sortKeys(ready)
+
+		var (
+			layer = make([]Key, len(ready))
+			next  []internal.Key
+		)
+
+		for i, key := range ready {
+			layer[i] = newKey(key)
+			delete(degrees, key)
+
+			for end := range g.edges[key] {
+				if degrees[end]--; degrees[end] == 0 {
+					next = append(next, end)
+				}
+			}
+		}
+
+		layers = append(layers, layer)
+		ready = next
+	}
+
+	if len(degrees) > 0 {
+		return nil, &CycleError{
+			Err:   ErrCycle,
+			Cycle: g.findCycleUnsafe(degrees),
+		}
+	}
+
+	return layers, nil
+}
+
+// findCycleUnsafe returns a cycle among the given vertices, each of which must
+// have at least one predecessor that is also among them.
+func (g *Graph) findCycleUnsafe(remaining map[internal.Key]int) Path {
+	var (
+		start = minKey(remaining)
+		index = map[internal.Key]int{start: 0}
+		walk  = []internal.Key{start}
+	)
+
+	// Walk backward from start until a vertex repeats; the walk from the
+	// repeated vertex onward is a cycle (in reverse).
+	for cur := start; ; {
+		var preds []internal.Key
+		for prev := range g.redges[cur] {
+			if _, ok := remaining[prev]; ok {
+				preds = append(preds, prev)
+			}
+		}
+
+		sortKeys(preds)
+		cur = preds[0]
+
+		if i, seen := index[cur]; seen {
+			walk = append(walk[i:], cur)
+			break
+		}
+
+		index[cur] = len(walk)
+		walk = append(walk, cur)
+	}
+
+	reverseKeys(walk)
+
+	cycle := internal.Path{Vertices: walk}
+	for i := 1; i < len(walk); i++ {
+		cycle.Cost += g.edges[walk[i-1]][walk[i]].cheapest().cost
+	}
+
+	return newPathFromInternal(cycle)
+}
+
+func minKey(keys map[internal.Key]int) internal.Key {
+	var (
+		min   internal.Key
+		found bool
+	)
+
+	for key := range keys {
+		if !found || key < min {
+			min, found = key, true
+		}
+	}
+
+	return min
+}
+
+func sortKeys(keys []internal.Key) {
+	sort.Slice(keys, func(i int, j int) bool {
+		return keys[i] < keys[j]
+	})
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTopologicalSort(t *testing.T) {
+	var (
+		g     = graph.New()
+		shirt = g.AddVertex("shirt")
+		tie   = g.AddVertex("tie")
+		belt  = g.AddVertex("belt")
+		pants = g.AddVertex("pants")
+		shoes = g.AddVertex("shoes")
+		socks = g.AddVertex("socks")
+		watch = g.AddVertex("watch")
+	)
+
+	g.AddEdge(shirt, tie)
+	g.AddEdge(shirt, belt)
+	g.AddEdge(pants, belt)
+	g.AddEdge(pants, shoes)
+	g.AddEdge(socks, shoes)
+
+	layers, err := g.TopologicalLayers()
+	require.NoError(t, err)
+	require.Equal(t, [][]graph.Key{
+		{shirt, pants, socks, watch},
+		{tie, belt, shoes},
+	}, layers)
+
+	keys, err := g.TopologicalSort()
+	require.NoError(t, err)
+	require.Equal(t, []graph.Key{
+		shirt, pants, socks, watch, tie, belt, shoes,
+	}, keys)
+
+	position := make(map[graph.Key]int, len(keys))
+	for i, key := range keys {
+		position[key] = i
+	}
+
+	g.VisitEdges(graph.Root, func(edge graph.Edge) bool {
+		require.Less(t, position[edge.Start.Key()], position[edge.End.Key()])
+		return true
+	})
+}
+
+func TestTopologicalSortEmpty(t *testing.T) {
+	keys, err := graph.New().TopologicalSort()
+	require.NoError(t, err)
+	require.Empty(t, keys)
+}
+
+func TestTopologicalSortCycle(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+		k4 = g.AddVertex(4)
+		k5 = g.AddVertex(5)
+	)
+
+	g.AddEdge(k1, k2)
+	g.AddEdgeCost(k2, k3, 2)
+	g.AddEdgeCost(k3, k4, 3)
+	g.AddEdgeCost(k4, k2, 4)
+	g.AddEdge(k4, k5)
+
+	keys, err := g.TopologicalSort()
+	require.Nil(t, keys)
+	require.True(t, errors.Is(err, graph.ErrCycle))
+
+	var cycleErr *graph.CycleError
+	require.True(t, errors.As(err, &cycleErr))
+	require.Equal(t, graph.Path{
+		Cost:     9,
+		Vertices: []graph.Key{k2, k3, k4, k2},
+	}, cycleErr.Cycle)
+
+	g.DeleteEdge(k4, k2)
+	g.AddEdge(k5, k5)
+
+	_, err = g.TopologicalLayers()
+	require.True(t, errors.As(err, &cycleErr))
+	require.Equal(t, graph.Path{
+		Cost:     1,
+		Vertices: []graph.Key{k5, k5},
+	}, cycleErr.Cycle)
+}
@@ -0,0 +1,268 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph
+
+import (
+	"github.com/mway/pkg/x/container/graph/internal"
+	"github.com/mway/pkg/x/container/tree"
+)
+
+// TraversalOrder determines graph traversal order. It is shared with package
+// tree, so the same orders apply to both containers.
+type TraversalOrder = tree.TraversalOrder
+
+// Available traversal orders. LevelOrder is a breadth-first traversal, while
+// PreOrder and PostOrder are depth-first traversals that visit each vertex
+// before or after its descendants, respectively.
+const (
+	LevelOrder   = tree.LevelOrder
+	PreOrder     = tree.PreOrder
+	PostOrder    = tree.PostOrder
+	DefaultOrder = tree.DefaultOrder
+)
+
+// Direction determines which edges are followed during a traversal.
+type Direction int
+
+// Available traversal directions. Outgoing follows edges from their start
+// vertex to their end vertex (e.g. from a task to its dependencies), while
+// Incoming follows edges in reverse (e.g. from a task to its dependents). For
+// undirected graphs, both directions are equivalent.
+const (
+	Outgoing Direction = iota
+	Incoming
+)
+
+// A Visit describes a vertex reached during a traversal.
+type Visit struct {
+	// Vertex is the visited vertex.
+	Vertex Vertex
+	// Parent is the key of the vertex from which Vertex was reached, or the
+	// zero Key if Vertex is the root of the traversal.
+	Parent Key
+	// Depth is the number of edges spanning the root of the traversal and
+	// Vertex along the traversal tree.
+	Depth int
+}
+
+// TraversalVisitorFunc is used by Graph to yield visited vertices, along with
+// their traversal context, to callers.
+type TraversalVisitorFunc = func(Visit) bool
+
+// Traverse uses fn to visit each vertex reachable from the vertex key, in the
+// given order and following edges in the given direction. Each vertex is
+// visited at most once. If key is Root, every vertex in the graph is visited,
+// starting new traversals (at depth 0) from unvisited vertices in key order.
+//
+// Neighbors are traversed in key order, so traversals are deterministic.
+// Traversal stops when fn returns false.
+//
+// The neighbors of each vertex are read when the vertex is visited, so fn may
+// modify g: edges added to or removed from vertices that have not yet been
+// visited affect the remainder of the traversal.
+func (g *Graph) Traverse(
+	key Key,
+	order TraversalOrder,
+	direction Direction,
+	fn TraversalVisitorFunc,
+) {
+	t := traversal{
+		vertex: func(key internal.Key) (Vertex, bool) {
+			g.mtx.RLock()
+			defer g.mtx.RUnlock()
+
+			vertex, ok := g.vertices[key]
+			return vertex, ok
+		},
+		adjacent: func(key internal.Key) []internal.Key {
+			g.mtx.RLock()
+			defer g.mtx.RUnlock()
+
+			edges := g.edges
+			if direction == Incoming {
+				edges = g.redges
+			}
+
+			keys := make([]internal.Key, 0, len(edges[key]))
+			for next := range edges[key] {
+				keys = append(keys, next)
+			}
+
+			sortKeys(keys)
+			return keys
+		},
+		visited: make(map[internal.Key]struct{}),
+		fn:      fn,
+	}
+
+	roots := []internal.Key{key.key}
+	if key == Root {
+		g.mtx.RLock()
+		roots = make([]internal.Key, 0, len(g.vertices))
+		for key := range g.vertices {
+			roots = append(roots, key)
+		}
+		g.mtx.RUnlock()
+
+		sortKeys(roots)
+	}
+
+	t.run(roots, order)
+}
+
+// A traversal is the state of a single traversal. It accesses the traversed
+// graph only through vertex and adjacent, which returns the neighbors of a
+// vertex in key order, and thus may traverse any representation of a graph.
+type traversal struct {
+	vertex   func(internal.Key) (Vertex, bool)
+	adjacent func(internal.Key) []internal.Key
+	visited  map[internal.Key]struct{}
+	fn       TraversalVisitorFunc
+}
+
+// run traverses the vertices reachable from each of roots in turn, until fn
+// stops the traversal.
+func (t *traversal) run(roots []internal.Key, order TraversalOrder) {
+	for _, root := range roots {
+		if !t.visit(root, order) {
+			return
+		}
+	}
+}
+
+// visit traverses the vertices reachable from root, returning false if the
+// traversal was stopped.
+func (t *traversal) visit(root internal.Key, order TraversalOrder) bool {
+	if _, ok := t.vertex(root); !ok {
+		return true
+	}
+
+	if _, ok := t.visited[root]; ok {
+		return true
+	}
+
+	switch order {
+	case PreOrder, PostOrder:
+		return t.depthFirst(root, order == PostOrder)
+	default:
+		return t.breadthFirst(root)
+	}
+}
+
+func (t *traversal) breadthFirst(root internal.Key) bool {
+	t.visited[root] = struct{}{}
+	queue := []Visit{t.newVisit(root, _zeroKey, 0)}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		if !t.fn(cur) {
+			return false
+		}
+
+		for _, next := range t.neighbors(cur.Vertex.key) {
+			t.visited[next] = struct{}{}
+			queue = append(queue, t.newVisit(next, cur.Vertex.Key(), cur.Depth+1))
+		}
+	}
+
+	return true
+}
+
+// depthFirstFrame tracks a visit and the unexplored neighbors of its vertex,
+// emulating recursion without risking stack exhaustion on deep graphs.
+type depthFirstFrame struct {
+	visit     Visit
+	neighbors []internal.Key
+}
+
+func (t *traversal) depthFirst(root internal.Key, post bool) bool {
+	var (
+		visit  = t.newVisit(root, _zeroKey, 0)
+		frames = []depthFirstFrame{{visit: visit}}
+	)
+
+	t.visited[root] = struct{}{}
+	if !post && !t.fn(visit) {
+		return false
+	}
+	frames[0].neighbors = t.neighbors(root)
+
+	for len(frames) > 0 {
+		top := &frames[len(frames)-1]
+
+		if len(top.neighbors) == 0 {
+			frames = frames[:len(frames)-1]
+			if post && !t.fn(top.visit) {
+				return false
+			}
+
+			continue
+		}
+
+		next := top.neighbors[0]
+		top.neighbors = top.neighbors[1:]
+
+		// A neighbor may have been visited via a sibling's descendants since
+		// top's neighbors were gathered.
+		if _, ok := t.visited[next]; ok {
+			continue
+		}
+
+		t.visited[next] = struct{}{}
+		visit := t.newVisit(next, top.visit.Vertex.Key(), top.visit.Depth+1)
+		if !post && !t.fn(visit) {
+			return false
+		}
+
+		frames = append(frames, depthFirstFrame{
+			visit:     visit,
+			neighbors: t.neighbors(next),
+		})
+	}
+
+	return true
+}
+
+// neighbors returns the unvisited neighbors of key, in key order.
+func (t *traversal) neighbors(key internal.Key) []internal.Key {
+	keys := t.adjacent(key)
+
+	unvisited := keys[:0]
+	for _, next := range keys {
+		if _, ok := t.visited[next]; !ok {
+			unvisited = append(unvisited, next)
+		}
+	}
+
+	return unvisited
+}
+
+func (t *traversal) newVisit(key internal.Key, parent Key, depth int) Visit {
+	vertex, _ := t.vertex(key)
+
+	return Visit{
+		Vertex: vertex,
+		Parent: parent,
+		Depth:  depth,
+	}
+}
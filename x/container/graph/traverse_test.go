@@ -0,0 +1,244 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/mway/pkg/x/container/graph"
+	"github.com/stretchr/testify/require"
+)
+
+type visit struct {
+	Value  interface{}
+	Parent interface{}
+	Depth  int
+}
+
+func TestTraverse(t *testing.T) {
+	var (
+		g    = graph.New()
+		k1   = g.AddVertex(1)
+		k2   = g.AddVertex(2)
+		k3   = g.AddVertex(3)
+		k4   = g.AddVertex(4)
+		k5   = g.AddVertex(5)
+		_    = g.AddVertex(6)
+		none = graph.Key{}
+	)
+
+	g.AddEdge(k1, k2)
+	g.AddEdge(k1, k3)
+	g.AddEdge(k2, k4)
+	g.AddEdge(k3, k4)
+	g.AddEdge(k4, k5)
+
+	cases := []struct {
+		name      string
+		key       graph.Key
+		order     graph.TraversalOrder
+		direction graph.Direction
+		expected  []visit
+	}{
+		{
+			name:      "level outgoing",
+			key:       k1,
+			order:     graph.LevelOrder,
+			direction: graph.Outgoing,
+			expected: []visit{
+				{Value: 1, Parent: nil, Depth: 0},
+				{Value: 2, Parent: 1, Depth: 1},
+				{Value: 3, Parent: 1, Depth: 1},
+				{Value: 4, Parent: 2, Depth: 2},
+				{Value: 5, Parent: 4, Depth: 3},
+			},
+		},
+		{
+			name:      "pre outgoing",
+			key:       k1,
+			order:     graph.PreOrder,
+			direction: graph.Outgoing,
+			expected: []visit{
+				{Value: 1, Parent: nil, Depth: 0},
+				{Value: 2, Parent: 1, Depth: 1},
+				{Value: 4, Parent: 2, Depth: 2},
+				{Value: 5, Parent: 4, Depth: 3},
+				{Value: 3, Parent: 1, Depth: 1},
+			},
+		},
+		{
+			name:      "post outgoing",
+			key:       k1,
+			order:     graph.PostOrder,
+			direction: graph.Outgoing,
+			expected: []visit{
+				{Value: 5, Parent: 4, Depth: 3},
+				{Value: 4, Parent: 2, Depth: 2},
+				{Value: 2, Parent: 1, Depth: 1},
+				{Value: 3, Parent: 1, Depth: 1},
+				{Value: 1, Parent: nil, Depth: 0},
+			},
+		},
+		{
+			name:      "level incoming",
+			key:       k4,
+			order:     graph.LevelOrder,
+			direction: graph.Incoming,
+			expected: []visit{
+				{Value: 4, Parent: nil, Depth: 0},
+				{Value: 2, Parent: 4, Depth: 1},
+				{Value: 3, Parent: 4, Depth: 1},
+				{Value: 1, Parent: 2, Depth: 2},
+			},
+		},
+		{
+			name:      "post incoming",
+			key:       k5,
+			order:     graph.PostOrder,
+			direction: graph.Incoming,
+			expected: []visit{
+				{Value: 1, Parent: 2, Depth: 3},
+				{Value: 2, Parent: 4, Depth: 2},
+				{Value: 3, Parent: 4, Depth: 2},
+				{Value: 4, Parent: 5, Depth: 1},
+				{Value: 5, Parent: nil, Depth: 0},
+			},
+		},
+		{
+			name:      "pre root",
+			key:       graph.Root,
+			order:     graph.PreOrder,
+			direction: graph.Outgoing,
+			expected: []visit{
+				{Value: 1, Parent: nil, Depth: 0},
+				{Value: 2, Parent: 1, Depth: 1},
+				{Value: 4, Parent: 2, Depth: 2},
+				{Value: 5, Parent: 4, Depth: 3},
+				{Value: 3, Parent: 1, Depth: 1},
+				{Value: 6, Parent: nil, Depth: 0},
+			},
+		},
+		{
+			name:      "level root incoming",
+			key:       graph.Root,
+			order:     graph.DefaultOrder,
+			direction: graph.Incoming,
+			expected: []visit{
+				{Value: 1, Parent: nil, Depth: 0},
+				{Value: 2, Parent: nil, Depth: 0},
+				{Value: 3, Parent: nil, Depth: 0},
+				{Value: 4, Parent: nil, Depth: 0},
+				{Value: 5, Parent: nil, Depth: 0},
+				{Value: 6, Parent: nil, Depth: 0},
+			},
+		},
+		{
+			name:      "missing",
+			key:       none,
+			order:     graph.PreOrder,
+			direction: graph.Outgoing,
+			expected:  nil,
+		},
+	}
+
+	// Visitors may not call back into g, so values are resolved up front.
+	values := make(map[graph.Key]interface{})
+	g.VisitVertices(graph.Root, func(vertex graph.Vertex) bool {
+		values[vertex.Key()] = vertex.Value()
+		return true
+	})
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []visit
+
+			g.Traverse(tc.key, tc.order, tc.direction, func(v graph.Visit) bool {
+				parent, ok := values[v.Parent]
+				require.Equal(t, v.Depth > 0, ok)
+
+				actual = append(actual, visit{
+					Value:  v.Vertex.Value(),
+					Parent: parent,
+					Depth:  v.Depth,
+				})
+				return true
+			})
+
+			require.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestTraverseStop(t *testing.T) {
+	var (
+		g  = graph.New()
+		k1 = g.AddVertex(1)
+		k2 = g.AddVertex(2)
+		k3 = g.AddVertex(3)
+	)
+
+	g.AddEdge(k1, k2)
+	g.AddEdge(k2, k3)
+
+	orders := []graph.TraversalOrder{
+		graph.LevelOrder,
+		graph.PreOrder,
+		graph.PostOrder,
+	}
+
+	for _, order := range orders {
+		t.Run(string(order), func(t *testing.T) {
+			var n int
+			g.Traverse(graph.Root, order, graph.Outgoing, func(graph.Visit) bool {
+				n++
+				return n < 2
+			})
+
+			require.Equal(t, 2, n)
+		})
+	}
+}
+
+func TestTraverseMutate(t *testing.T) {
+	var (
+		g       = graph.New()
+		k1      = g.AddVertex(1)
+		k2      = g.AddVertex(2)
+		visited []interface{}
+	)
+
+	g.AddEdge(k1, k2)
+
+	// Each visited vertex grows the graph by one vertex, up to five, which is
+	// visited in turn.
+	g.Traverse(k1, graph.PreOrder, graph.Outgoing, func(v graph.Visit) bool {
+		visited = append(visited, v.Vertex.Value())
+
+		if n := g.Order(); n < 5 {
+			g.AddEdge(v.Vertex.Key(), g.AddVertex(n+1))
+		}
+
+		return true
+	})
+
+	require.Equal(t, []interface{}{1, 2, 4, 5, 3}, visited)
+	require.Equal(t, 5, g.Order())
+}
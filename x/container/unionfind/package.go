@@ -0,0 +1,3 @@
+// Package unionfind provides a simple disjoint-set (union-find)
+// implementation.
+package unionfind
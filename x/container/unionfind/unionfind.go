@@ -0,0 +1,157 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package unionfind
+
+import (
+	"sync"
+)
+
+// A UnionFind is a disjoint-set forest: it tracks a collection of elements
+// partitioned into non-overlapping sets, and supports efficiently merging sets
+// and determining which set an element belongs to. Elements must be
+// comparable, as they are used as map keys.
+//
+// The zero value is an empty UnionFind ready for use.
+type UnionFind struct {
+	mtx    sync.Mutex
+	parent map[interface{}]interface{}
+	rank   map[interface{}]int
+	sets   int
+}
+
+// Add adds x to u as a new singleton set. If x already exists in u, Add does
+// nothing and returns false.
+func (u *UnionFind) Add(x interface{}) bool {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	return u.addUnsafe(x)
+}
+
+// Connected returns whether x and y both exist in u and belong to the same set.
+func (u *UnionFind) Connected(x interface{}, y interface{}) bool {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	rx, ok := u.findUnsafe(x)
+	if !ok {
+		return false
+	}
+
+	ry, ok := u.findUnsafe(y)
+	if !ok {
+		return false
+	}
+
+	return rx == ry
+}
+
+// Find returns the representative element of the set containing x, and whether
+// x exists in u. Two elements belong to the same set if and only if they have
+// the same representative; representatives may change as sets are merged.
+func (u *UnionFind) Find(x interface{}) (interface{}, bool) {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	return u.findUnsafe(x)
+}
+
+// Len returns the number of elements in u.
+func (u *UnionFind) Len() int {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	return len(u.parent)
+}
+
+// Sets returns the number of disjoint sets in u.
+func (u *UnionFind) Sets() int {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	return u.sets
+}
+
+// Union merges the sets containing x and y, adding either element to u first
+// if it does not already exist. Union returns true if x and y belonged to
+// different sets prior to the call.
+func (u *UnionFind) Union(x interface{}, y interface{}) bool {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	u.addUnsafe(x)
+	u.addUnsafe(y)
+
+	rx, _ := u.findUnsafe(x)
+	ry, _ := u.findUnsafe(y)
+
+	if rx == ry {
+		return false
+	}
+
+	// Union by rank keeps trees shallow: the shorter tree is always attached
+	// beneath the taller one.
+	switch {
+	case u.rank[rx] < u.rank[ry]:
+		u.parent[rx] = ry
+	case u.rank[rx] > u.rank[ry]:
+		u.parent[ry] = rx
+	default:
+		u.parent[ry] = rx
+		u.rank[rx]++
+	}
+
+	u.sets--
+
+	return true
+}
+
+func (u *UnionFind) addUnsafe(x interface{}) bool {
+	if u.parent == nil {
+		u.parent = make(map[interface{}]interface{})
+		u.rank = make(map[interface{}]int)
+	}
+
+	if _, exists := u.parent[x]; exists {
+		return false
+	}
+
+	u.parent[x] = x
+	u.sets++
+
+	return true
+}
+
+func (u *UnionFind) findUnsafe(x interface{}) (interface{}, bool) {
+	parent, ok := u.parent[x]
+	if !ok {
+		return nil, false
+	}
+
+	// Path halving: point every other element on the path at its grandparent.
+	for parent != x {
+		grandparent := u.parent[parent]
+		u.parent[x] = grandparent
+		x, parent = grandparent, u.parent[grandparent]
+	}
+
+	return x, true
+}
@@ -0,0 +1,81 @@
+// Copyright (c) 2020 Matt Way
+//
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+
+package unionfind_test
+
+import (
+	"testing"
+
+	"github.com/mway/pkg/x/container/unionfind"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnionFind(t *testing.T) {
+	var u unionfind.UnionFind
+
+	require.Equal(t, 0, u.Len())
+	require.Equal(t, 0, u.Sets())
+	require.False(t, u.Connected(1, 1))
+
+	_, ok := u.Find(1)
+	require.False(t, ok)
+
+	for i := 0; i < 10; i++ {
+		require.True(t, u.Add(i))
+	}
+
+	require.False(t, u.Add(0))
+	require.Equal(t, 10, u.Len())
+	require.Equal(t, 10, u.Sets())
+
+	// Merge evens and odds into two sets.
+	for i := 2; i < 10; i++ {
+		require.True(t, u.Union(i-2, i))
+	}
+
+	require.False(t, u.Union(0, 8))
+	require.Equal(t, 10, u.Len())
+	require.Equal(t, 2, u.Sets())
+
+	for i := 0; i < 10; i++ {
+		for j := 0; j < 10; j++ {
+			require.Equal(t, i%2 == j%2, u.Connected(i, j))
+		}
+	}
+
+	even, ok := u.Find(4)
+	require.True(t, ok)
+
+	odd, ok := u.Find(7)
+	require.True(t, ok)
+	require.NotEqual(t, even, odd)
+
+	// Union adds missing elements.
+	require.True(t, u.Union(even, "x"))
+	require.Equal(t, 11, u.Len())
+	require.Equal(t, 2, u.Sets())
+	require.True(t, u.Connected("x", 0))
+	require.False(t, u.Connected("x", 1))
+	require.False(t, u.Connected("x", "y"))
+
+	require.True(t, u.Union(1, 0))
+	require.Equal(t, 1, u.Sets())
+	require.True(t, u.Connected("x", 9))
+}
@@ -1,6 +1,7 @@
 package canceler
 
 import (
+	"context"
 	"sync"
 )
 
@@ -89,3 +90,24 @@ func (c *Canceler) Cancel() {
 		close(ch)
 	}
 }
+
+// Context returns a copy of parent that is canceled when c is canceled, when
+// parent is done, or when the returned CancelFunc is called, whichever happens
+// first. This allows a Canceler to be used with APIs that accept a
+// context.Context. The returned CancelFunc should be called once the context
+// is no longer needed, to release its resources.
+func (c *Canceler) Context(
+	parent context.Context,
+) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+
+	go func() {
+		select {
+		case <-c.C():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, cancel
+}
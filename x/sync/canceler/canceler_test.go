@@ -84,3 +84,48 @@ func TestCancelerInheritance(t *testing.T) {
 		}
 	}
 }
+
+func TestCancelerContext(t *testing.T) {
+	var c canceler.Canceler
+
+	ctx, cancel := c.Context(context.Background())
+	defer cancel()
+
+	select {
+	case <-ctx.Done():
+		require.Fail(t, "context done before cancellation")
+	default:
+	}
+
+	c.Cancel()
+
+	timeout, cancelTimeout := context.WithTimeout(
+		context.Background(),
+		time.Second,
+	)
+	defer cancelTimeout()
+
+	select {
+	case <-ctx.Done():
+		require.Equal(t, context.Canceled, ctx.Err())
+	case <-timeout.Done():
+		require.Fail(t, "canceler did not cancel context")
+	}
+}
+
+func TestCancelerContextParent(t *testing.T) {
+	var c canceler.Canceler
+
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := c.Context(parent)
+	defer cancel()
+
+	cancelParent()
+	<-ctx.Done()
+
+	select {
+	case <-c.C():
+		require.Fail(t, "context canceled canceler")
+	default:
+	}
+}